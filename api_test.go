@@ -0,0 +1,534 @@
+package main
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"testing"
+
+	"w4/lc3/internal/repository"
+	"w4/lc3/internal/service"
+)
+
+// longEmail is a valid address of more than the 100 characters users.email holds
+var longEmail = "bob@" + strings.Repeat("a", 50) + "." + strings.Repeat("b", 50) + ".com"
+
+func TestRegisterAndLogin(t *testing.T) {
+	api := newTestAPI(t)
+	alice := api.register("alice")
+
+	t.Run("register rejects invalid input", func(t *testing.T) {
+		api := api.on(t)
+		api.expect(http.StatusBadRequest, http.MethodPost, "/users/register", "", `{"name": "Bob",`)
+		resp := api.expect(http.StatusBadRequest, http.MethodPost, "/users/register", "", map[string]string{
+			"name": "Bob", "email": "not-an-email", "password": "short",
+		})
+		if code := resp.code(t); code != "VALIDATION_FAILED" {
+			t.Errorf("code %s, want VALIDATION_FAILED", code)
+		}
+
+		// longer than users.email holds
+		resp = api.expect(http.StatusBadRequest, http.MethodPost, "/users/register", "", map[string]string{
+			"name": "Bob", "email": longEmail, "password": testPassword,
+		})
+		var body struct {
+			Errors []struct {
+				Field string `json:"field"`
+				Rule  string `json:"rule"`
+			} `json:"errors"`
+		}
+		resp.decode(t, &body)
+		if len(body.Errors) != 1 || body.Errors[0].Field != "email" || body.Errors[0].Rule != "max" {
+			t.Errorf("errors %+v, want the max rule of email", body.Errors)
+		}
+	})
+
+	t.Run("register rejects a taken email", func(t *testing.T) {
+		api := api.on(t)
+		resp := api.expect(http.StatusBadRequest, http.MethodPost, "/users/register", "", map[string]string{
+			"name": "Alice Again", "email": alice.Email, "password": testPassword,
+		})
+		if code := resp.code(t); code != "EMAIL_TAKEN" {
+			t.Errorf("code %s, want EMAIL_TAKEN", code)
+		}
+	})
+
+	t.Run("login", func(t *testing.T) {
+		api := api.on(t)
+		u := api.login(alice)
+		if u.Token == "" || u.RefreshToken == "" {
+			t.Fatalf("login returned no tokens")
+		}
+		api.expect(http.StatusOK, http.MethodGet, "/users/carts", u.Token, nil)
+	})
+
+	t.Run("login rejects wrong credentials", func(t *testing.T) {
+		api := api.on(t)
+		api.expect(http.StatusBadRequest, http.MethodPost, "/users/login", "", "not json")
+		api.expect(http.StatusBadRequest, http.MethodPost, "/users/login", "", map[string]string{"email": alice.Email})
+		for _, creds := range []map[string]string{
+			{"email": alice.Email, "password": "wrong-password1"},
+			{"email": "nobody-" + alice.Email, "password": testPassword},
+		} {
+			resp := api.expect(http.StatusUnauthorized, http.MethodPost, "/users/login", "", creds)
+			if code := resp.code(t); code != "INVALID_CREDENTIALS" {
+				t.Errorf("code %s, want INVALID_CREDENTIALS", code)
+			}
+		}
+	})
+
+	t.Run("protected routes need a valid token", func(t *testing.T) {
+		api := api.on(t)
+		api.expect(http.StatusUnauthorized, http.MethodGet, "/users/carts", "", nil)
+		api.expect(http.StatusUnauthorized, http.MethodGet, "/users/orders", "not-a-jwt", nil)
+		api.expect(http.StatusUnauthorized, http.MethodPost, "/users/orders", "", nil)
+	})
+
+	t.Run("public keys", func(t *testing.T) {
+		api := api.on(t)
+		api.expect(http.StatusOK, http.MethodGet, "/.well-known/jwks.json", "", nil)
+	})
+}
+
+func TestSessions(t *testing.T) {
+	api := newTestAPI(t)
+	u := api.signUp("alice")
+
+	// a refresh token works once, replaying it ends the whole session
+	resp := api.expect(http.StatusOK, http.MethodPost, "/users/refresh", "", map[string]string{"refresh_token": u.RefreshToken})
+	var pair service.TokenPair
+	resp.decode(t, &pair)
+	api.expect(http.StatusUnauthorized, http.MethodPost, "/users/refresh", "", map[string]string{"refresh_token": u.RefreshToken})
+	api.expect(http.StatusUnauthorized, http.MethodPost, "/users/refresh", "", map[string]string{"refresh_token": pair.RefreshToken})
+	api.expect(http.StatusBadRequest, http.MethodPost, "/users/refresh", "", map[string]string{})
+
+	// logging out revokes the access token
+	u = api.login(u)
+	api.expect(http.StatusNoContent, http.MethodPost, "/users/logout", u.Token, map[string]string{"refresh_token": u.RefreshToken})
+	resp = api.expect(http.StatusUnauthorized, http.MethodGet, "/users/me", u.Token, nil)
+	if code := resp.code(t); code != "TOKEN_REVOKED" {
+		t.Errorf("code %s, want TOKEN_REVOKED", code)
+	}
+	api.expect(http.StatusUnauthorized, http.MethodPost, "/users/refresh", "", map[string]string{"refresh_token": u.RefreshToken})
+}
+
+func TestEmailVerification(t *testing.T) {
+	api := newTestAPI(t)
+	u := api.login(api.register("alice"))
+
+	api.expect(http.StatusBadRequest, http.MethodPost, "/users/verify", "", map[string]string{"token": "bogus"})
+
+	// registering sent the first email, another one has to wait
+	resp := api.expect(http.StatusTooManyRequests, http.MethodPost, "/users/verify/resend", u.Token, nil)
+	// at most the one minute cooldown, the send time and the check share the database clock
+	if wait, err := strconv.Atoi(resp.Header.Get("Retry-After")); err != nil || wait < 1 || wait > 60 {
+		t.Errorf("Retry-After %q, want 1 to 60 seconds", resp.Header.Get("Retry-After"))
+	}
+
+	api.expect(http.StatusOK, http.MethodPost, "/users/verify", "", map[string]string{"token": api.mailedToken(u.Email)})
+	api.expect(http.StatusConflict, http.MethodPost, "/users/verify/resend", u.Token, nil)
+}
+
+func TestPasswordReset(t *testing.T) {
+	api := newTestAPI(t)
+	u := api.signUp("alice")
+
+	// unknown emails get the same answer
+	api.expect(http.StatusAccepted, http.MethodPost, "/users/password/forgot", "", map[string]string{"email": "nobody-" + u.Email})
+	api.expect(http.StatusBadRequest, http.MethodPost, "/users/password/forgot", "", map[string]string{"email": "nope"})
+
+	api.expect(http.StatusAccepted, http.MethodPost, "/users/password/forgot", "", map[string]string{"email": u.Email})
+	token := api.mailedToken(u.Email)
+	reset := map[string]string{"token": token, "password": "another456"}
+	api.expect(http.StatusOK, http.MethodPost, "/users/password/reset", "", reset)
+	api.expect(http.StatusBadRequest, http.MethodPost, "/users/password/reset", "", reset)
+
+	// the old session and password are gone
+	api.expect(http.StatusUnauthorized, http.MethodPost, "/users/refresh", "", map[string]string{"refresh_token": u.RefreshToken})
+	api.expect(http.StatusUnauthorized, http.MethodPost, "/users/login", "", map[string]string{"email": u.Email, "password": testPassword})
+	api.expect(http.StatusOK, http.MethodPost, "/users/login", "", map[string]string{"email": u.Email, "password": "another456"})
+
+	// a mail failure doesn't tell registered emails apart either
+	api.mail.setDown(true)
+	api.expect(http.StatusAccepted, http.MethodPost, "/users/password/forgot", "", map[string]string{"email": u.Email})
+	api.expect(http.StatusAccepted, http.MethodPost, "/users/password/forgot", "", map[string]string{"email": "nobody-" + u.Email})
+}
+
+func TestProfile(t *testing.T) {
+	api := newTestAPI(t)
+	u := api.signUp("alice")
+
+	resp := api.expect(http.StatusOK, http.MethodGet, "/users/me", u.Token, nil)
+	var profile service.Profile
+	resp.decode(t, &profile)
+	if profile.Email != u.Email {
+		t.Errorf("profile email %s, want %s", profile.Email, u.Email)
+	}
+
+	api.expect(http.StatusOK, http.MethodPatch, "/users/me", u.Token, map[string]string{"name": "Alice Renamed"})
+	api.expect(http.StatusBadRequest, http.MethodPatch, "/users/me", u.Token, map[string]string{})
+	api.expect(http.StatusBadRequest, http.MethodPatch, "/users/me", u.Token, map[string]string{"name": "4lice"})
+	api.expect(http.StatusBadRequest, http.MethodPatch, "/users/me", u.Token, map[string]string{"email": longEmail})
+
+	change := map[string]string{"current_password": "wrong-password1", "new_password": "another456"}
+	api.expect(http.StatusBadRequest, http.MethodPost, "/users/me/password", u.Token, change)
+	change["current_password"] = testPassword
+	resp = api.expect(http.StatusOK, http.MethodPost, "/users/me/password", u.Token, change)
+	var pair service.TokenPair
+	resp.decode(t, &pair)
+
+	api.expect(http.StatusBadRequest, http.MethodDelete, "/users/me", pair.Token, map[string]string{"password": testPassword})
+	api.expect(http.StatusNoContent, http.MethodDelete, "/users/me", pair.Token, map[string]string{"password": "another456"})
+	api.expect(http.StatusUnauthorized, http.MethodPost, "/users/login", "", map[string]string{"email": u.Email, "password": "another456"})
+}
+
+func TestProducts(t *testing.T) {
+	api := newTestAPI(t)
+	admin := api.admin()
+	customer := api.signUp("alice")
+	p := api.product(admin, "widget", 25000, 10)
+
+	t.Run("list", func(t *testing.T) {
+		api := api.on(t)
+		resp := api.expect(http.StatusOK, http.MethodGet, "/products?name="+url.QueryEscape(p.Name), "", nil)
+		var page struct {
+			Products []repository.Product `json:"products"`
+		}
+		resp.decode(t, &page)
+		if len(page.Products) != 1 || page.Products[0].ProductID != p.ProductID {
+			t.Errorf("products %+v, want only %d", page.Products, p.ProductID)
+		}
+		api.expect(http.StatusBadRequest, http.MethodGet, "/products?limit=0", "", nil)
+		api.expect(http.StatusBadRequest, http.MethodGet, "/products?sort=cheapest", "", nil)
+		api.expect(http.StatusBadRequest, http.MethodGet, "/products?page=2305843009213693953&limit=5", "", nil)
+		api.expect(http.StatusBadRequest, http.MethodGet, "/products/search?q=widget&page=2305843009213693953&limit=5", "", nil)
+	})
+
+	t.Run("cursor keeps its sort", func(t *testing.T) {
+		api := api.on(t)
+		api.product(admin, "gadget", 30000, 1)
+		resp := api.expect(http.StatusOK, http.MethodGet, "/products?limit=1&sort=price", "", nil)
+		var page struct {
+			Pagination struct {
+				NextCursor string `json:"next_cursor"`
+			} `json:"pagination"`
+		}
+		resp.decode(t, &page)
+		if page.Pagination.NextCursor == "" {
+			t.Fatalf("no next cursor: %s", resp.Body)
+		}
+		after := url.QueryEscape(page.Pagination.NextCursor)
+		api.expect(http.StatusOK, http.MethodGet, "/products?limit=1&sort=price&after="+after, "", nil)
+		api.expect(http.StatusBadRequest, http.MethodGet, "/products?limit=1&sort=name&after="+after, "", nil)
+		api.expect(http.StatusBadRequest, http.MethodGet, "/products?limit=1&after="+after, "", nil)
+	})
+
+	t.Run("get", func(t *testing.T) {
+		api := api.on(t)
+		resp := api.expect(http.StatusOK, http.MethodGet, fmt.Sprintf("/products/%d", p.ProductID), "", nil)
+		var got repository.Product
+		resp.decode(t, &got)
+		if got.Name != p.Name || got.Stock != 10 {
+			t.Errorf("product %+v, want %+v", got, p)
+		}
+		api.expect(http.StatusNotFound, http.MethodGet, "/products/999999999", "", nil)
+		api.expect(http.StatusBadRequest, http.MethodGet, "/products/abc", "", nil)
+	})
+
+	t.Run("search", func(t *testing.T) {
+		api := api.on(t)
+		resp := api.expect(http.StatusOK, http.MethodGet, "/products/search?q=widget+"+tagOf(p), "", nil)
+		var result struct {
+			Results []repository.SearchResult `json:"results"`
+		}
+		resp.decode(t, &result)
+		if len(result.Results) != 1 || result.Results[0].ProductID != p.ProductID {
+			t.Errorf("search found %+v, want only %d", result.Results, p.ProductID)
+		}
+		api.expect(http.StatusBadRequest, http.MethodGet, "/products/search", "", nil)
+	})
+
+	t.Run("only admins manage products", func(t *testing.T) {
+		api := api.on(t)
+		body := map[string]any{"name": "Stolen", "price": 1}
+		api.expect(http.StatusUnauthorized, http.MethodPost, "/products", "", body)
+		api.expect(http.StatusForbidden, http.MethodPost, "/products", customer.Token, body)
+		api.expect(http.StatusForbidden, http.MethodPut, fmt.Sprintf("/products/%d", p.ProductID), customer.Token, body)
+		api.expect(http.StatusForbidden, http.MethodPatch, fmt.Sprintf("/products/%d", p.ProductID), customer.Token, body)
+		api.expect(http.StatusForbidden, http.MethodDelete, fmt.Sprintf("/products/%d", p.ProductID), customer.Token, nil)
+		api.expect(http.StatusForbidden, http.MethodPost, fmt.Sprintf("/admin/products/%d/stock", p.ProductID), customer.Token,
+			map[string]any{"delta": 5, "reason": "restock"})
+	})
+
+	t.Run("create, update and delete", func(t *testing.T) {
+		api := api.on(t)
+		api.expect(http.StatusBadRequest, http.MethodPost, "/products", admin.Token, map[string]any{"name": " ", "price": 0})
+		api.expect(http.StatusBadRequest, http.MethodPost, "/products", admin.Token, map[string]any{"name": "Gold bar", "price": 1e9})
+
+		gadget := api.product(admin, "gadget", 1000, 0)
+		path := fmt.Sprintf("/products/%d", gadget.ProductID)
+		api.expect(http.StatusOK, http.MethodPut, path, admin.Token, map[string]any{"name": gadget.Name, "description": "Replaced", "price": 1500})
+		resp := api.expect(http.StatusOK, http.MethodPatch, path, admin.Token, map[string]any{"price": 1750})
+		api.expect(http.StatusBadRequest, http.MethodPatch, path, admin.Token, map[string]any{"price": 1e9}) // past DECIMAL(10, 2)
+		var patched repository.Product
+		resp.decode(t, &patched)
+		if patched.Price != 1750 || patched.Description != "Replaced" {
+			t.Errorf("patched product %+v", patched)
+		}
+		api.expect(http.StatusNotFound, http.MethodPut, "/products/999999999", admin.Token, map[string]any{"name": "Ghost", "price": 1})
+		api.expect(http.StatusNotFound, http.MethodPatch, "/products/999999999", admin.Token, map[string]any{"price": 1})
+
+		api.expect(http.StatusOK, http.MethodDelete, path, admin.Token, nil)
+		api.expect(http.StatusNotFound, http.MethodGet, path, "", nil)
+		api.expect(http.StatusNotFound, http.MethodDelete, path, admin.Token, nil)
+	})
+
+	t.Run("stock", func(t *testing.T) {
+		api := api.on(t)
+		path := fmt.Sprintf("/admin/products/%d/stock", p.ProductID)
+		resp := api.expect(http.StatusOK, http.MethodPost, path, admin.Token, map[string]any{"delta": 5, "reason": "restock"})
+		var adjusted struct {
+			Stock int `json:"stock"`
+		}
+		resp.decode(t, &adjusted)
+		if adjusted.Stock != 15 {
+			t.Errorf("stock %d, want 15", adjusted.Stock)
+		}
+		api.expect(http.StatusConflict, http.MethodPost, path, admin.Token, map[string]any{"delta": -100, "reason": "lost"})
+		api.expect(http.StatusBadRequest, http.MethodPost, path, admin.Token, map[string]any{"delta": 1, "reason": "gift"})
+		api.expect(http.StatusNotFound, http.MethodPost, "/admin/products/999999999/stock", admin.Token, map[string]any{"delta": 1, "reason": "restock"})
+	})
+}
+
+func TestCategories(t *testing.T) {
+	api := newTestAPI(t)
+	admin := api.admin()
+	parent := api.addCategory("Electronics", nil)
+	child := api.addCategory("Phones", &parent)
+	phone := api.product(admin, "phone", 5000000, 3)
+	api.categorize(phone.ProductID, child)
+
+	api.expect(http.StatusOK, http.MethodGet, "/categories", "", nil)
+
+	// products of subcategories are listed under their parents
+	resp := api.expect(http.StatusOK, http.MethodGet, fmt.Sprintf("/categories/%d/products", parent), "", nil)
+	var page struct {
+		Products []repository.Product `json:"products"`
+	}
+	resp.decode(t, &page)
+	if len(page.Products) != 1 || page.Products[0].ProductID != phone.ProductID {
+		t.Errorf("category products %+v, want only %d", page.Products, phone.ProductID)
+	}
+
+	api.expect(http.StatusNotFound, http.MethodGet, "/categories/999999999/products", "", nil)
+	api.expect(http.StatusBadRequest, http.MethodGet, "/categories/abc/products", "", nil)
+}
+
+func TestCart(t *testing.T) {
+	api := newTestAPI(t)
+	admin := api.admin()
+	u := api.signUp("alice")
+	other := api.signUp("bob")
+	widget := api.product(admin, "widget", 20000, 5)
+	gadget := api.product(admin, "gadget", 5000, 5)
+
+	add := func(api *testAPI, productID, quantity int) response {
+		return api.do(http.MethodPost, "/users/carts", u.Token, map[string]int{"product_id": productID, "quantity": quantity})
+	}
+
+	t.Run("add", func(t *testing.T) {
+		api := api.on(t)
+		if resp := add(api, widget.ProductID, 2); resp.Status != http.StatusCreated {
+			t.Fatalf("status %d: %s", resp.Status, resp.Body)
+		}
+		// adding again adds to the same line
+		if resp := add(api, widget.ProductID, 1); resp.Status != http.StatusCreated {
+			t.Fatalf("status %d: %s", resp.Status, resp.Body)
+		}
+		if resp := add(api, gadget.ProductID, 1); resp.Status != http.StatusCreated {
+			t.Fatalf("status %d: %s", resp.Status, resp.Body)
+		}
+	})
+
+	t.Run("add rejects invalid items", func(t *testing.T) {
+		api := api.on(t)
+		if resp := add(api, 999999999, 1); resp.Status != http.StatusNotFound {
+			t.Errorf("unknown product: status %d", resp.Status)
+		}
+		if resp := add(api, widget.ProductID, 0); resp.Status != http.StatusBadRequest {
+			t.Errorf("zero quantity: status %d", resp.Status)
+		}
+		resp := add(api, widget.ProductID, 10)
+		if resp.Status != http.StatusConflict || resp.code(t) != "INSUFFICIENT_STOCK" {
+			t.Errorf("more than in stock: status %d: %s", resp.Status, resp.Body)
+		}
+		api.expect(http.StatusBadRequest, http.MethodPost, "/users/carts", u.Token, `{"product_id": "one"}`)
+	})
+
+	var view service.CartView
+	resp := api.expect(http.StatusOK, http.MethodGet, "/users/carts", u.Token, nil)
+	resp.decode(t, &view)
+	if len(view.Cart) != 2 || view.Summary.ItemCount != 4 {
+		t.Fatalf("cart %+v, want 2 lines and 4 items", view)
+	}
+	var widgetLine repository.CartLine
+	for _, line := range view.Cart {
+		if line.ProductID == widget.ProductID {
+			widgetLine = line
+		}
+	}
+	if widgetLine.Quantity != 3 || widgetLine.LineSubtotal != 60000 {
+		t.Errorf("widget line %+v, want 3 for 60000", widgetLine)
+	}
+	path := fmt.Sprintf("/users/carts/%d", widgetLine.CartID)
+
+	t.Run("update", func(t *testing.T) {
+		api := api.on(t)
+		api.expect(http.StatusOK, http.MethodPatch, path, u.Token, map[string]int{"quantity": 1})
+		api.expect(http.StatusConflict, http.MethodPatch, path, u.Token, map[string]int{"quantity": 6})
+		api.expect(http.StatusBadRequest, http.MethodPatch, path, u.Token, map[string]int{"quantity": -1})
+		api.expect(http.StatusNotFound, http.MethodPatch, path, other.Token, map[string]int{"quantity": 1})
+	})
+
+	t.Run("delete", func(t *testing.T) {
+		api := api.on(t)
+		api.expect(http.StatusNotFound, http.MethodDelete, path, other.Token, nil)
+		api.expect(http.StatusBadRequest, http.MethodDelete, "/users/carts/abc", u.Token, nil)
+		api.expect(http.StatusOK, http.MethodDelete, path, u.Token, nil)
+		api.expect(http.StatusNotFound, http.MethodDelete, path, u.Token, nil)
+	})
+
+	t.Run("clear", func(t *testing.T) {
+		api := api.on(t)
+		api.expect(http.StatusOK, http.MethodDelete, "/users/carts", u.Token, nil)
+		var view service.CartView
+		api.expect(http.StatusOK, http.MethodGet, "/users/carts", u.Token, nil).decode(t, &view)
+		if len(view.Cart) != 0 {
+			t.Errorf("cart still has %d lines", len(view.Cart))
+		}
+	})
+}
+
+func TestOrders(t *testing.T) {
+	api := newTestAPI(t)
+	admin := api.admin()
+	u := api.signUp("alice")
+	other := api.signUp("bob")
+	widget := api.product(admin, "widget", 20000, 5)
+	stockPath := fmt.Sprintf("/admin/products/%d/stock", widget.ProductID)
+	addWidgets := func(api *testAPI, u user, quantity int) {
+		api.expect(http.StatusCreated, http.MethodPost, "/users/carts", u.Token, map[string]int{"product_id": widget.ProductID, "quantity": quantity})
+	}
+
+	t.Run("checkout needs a verified email", func(t *testing.T) {
+		api := api.on(t)
+		unverified := api.login(api.register("carol"))
+		addWidgets(api, unverified, 1)
+		resp := api.expect(http.StatusForbidden, http.MethodPost, "/users/orders", unverified.Token, nil)
+		if code := resp.code(t); code != "EMAIL_NOT_VERIFIED" {
+			t.Errorf("code %s, want EMAIL_NOT_VERIFIED", code)
+		}
+	})
+
+	t.Run("checkout rejects an empty cart", func(t *testing.T) {
+		api := api.on(t)
+		resp := api.expect(http.StatusBadRequest, http.MethodPost, "/users/orders", u.Token, nil)
+		if code := resp.code(t); code != "CART_EMPTY" {
+			t.Errorf("code %s, want CART_EMPTY", code)
+		}
+	})
+
+	t.Run("checkout rejects items out of stock", func(t *testing.T) {
+		api := api.on(t)
+		addWidgets(api, u, 3)
+		api.expect(http.StatusOK, http.MethodPost, stockPath, admin.Token, map[string]any{"delta": -4, "reason": "damaged"})
+		resp := api.expect(http.StatusConflict, http.MethodPost, "/users/orders", u.Token, nil)
+		if code := resp.code(t); code != "INSUFFICIENT_STOCK" {
+			t.Errorf("code %s, want INSUFFICIENT_STOCK", code)
+		}
+		api.expect(http.StatusOK, http.MethodPost, stockPath, admin.Token, map[string]any{"delta": 4, "reason": "correction"})
+	})
+
+	var orderID int
+	t.Run("checkout", func(t *testing.T) {
+		api := api.on(t)
+		resp := api.expect(http.StatusCreated, http.MethodPost, "/users/orders", u.Token, nil)
+		var receipt struct {
+			OrderID    int     `json:"order_id"`
+			TotalPrice float64 `json:"total_price"`
+		}
+		resp.decode(t, &receipt)
+		orderID = receipt.OrderID
+
+		var view service.CartView
+		api.expect(http.StatusOK, http.MethodGet, "/users/carts", u.Token, nil).decode(t, &view)
+		if len(view.Cart) != 0 {
+			t.Errorf("cart not emptied by checkout: %+v", view.Cart)
+		}
+		var p repository.Product
+		api.expect(http.StatusOK, http.MethodGet, fmt.Sprintf("/products/%d", widget.ProductID), "", nil).decode(t, &p)
+		if p.Stock != 2 {
+			t.Errorf("stock %d after ordering 3 of 5", p.Stock)
+		}
+	})
+	if orderID == 0 {
+		t.FailNow()
+	}
+	path := fmt.Sprintf("/users/orders/%d", orderID)
+
+	t.Run("history", func(t *testing.T) {
+		api := api.on(t)
+		var list struct {
+			Orders []repository.Order `json:"orders"`
+		}
+		api.expect(http.StatusOK, http.MethodGet, "/users/orders", u.Token, nil).decode(t, &list)
+		if len(list.Orders) != 1 || list.Orders[0].OrderID != orderID || list.Orders[0].Status != repository.StatusPending {
+			t.Errorf("orders %+v, want only pending order %d", list.Orders, orderID)
+		}
+
+		var detail service.OrderDetail
+		api.expect(http.StatusOK, http.MethodGet, path, u.Token, nil).decode(t, &detail)
+		if len(detail.Items) != 1 || detail.Items[0].Quantity != 3 || detail.Items[0].LineTotal != 60000 {
+			t.Errorf("order items %+v, want 3 widgets for 60000", detail.Items)
+		}
+
+		api.expect(http.StatusNotFound, http.MethodGet, path, other.Token, nil)
+		api.expect(http.StatusBadRequest, http.MethodGet, "/users/orders/abc", u.Token, nil)
+	})
+
+	t.Run("cancel", func(t *testing.T) {
+		api := api.on(t)
+		api.expect(http.StatusNotFound, http.MethodPost, path+"/cancel", other.Token, nil)
+		api.expect(http.StatusOK, http.MethodPost, path+"/cancel", u.Token, nil)
+		api.expect(http.StatusConflict, http.MethodPost, path+"/cancel", u.Token, nil)
+
+		// cancelling puts the items back in stock
+		var p repository.Product
+		api.expect(http.StatusOK, http.MethodGet, fmt.Sprintf("/products/%d", widget.ProductID), "", nil).decode(t, &p)
+		if p.Stock != 5 {
+			t.Errorf("stock %d after cancelling, want 5", p.Stock)
+		}
+	})
+
+	t.Run("status changes", func(t *testing.T) {
+		api := api.on(t)
+		addWidgets(api, u, 1)
+		var receipt struct {
+			OrderID int `json:"order_id"`
+		}
+		api.expect(http.StatusCreated, http.MethodPost, "/users/orders", u.Token, nil).decode(t, &receipt)
+		statusPath := fmt.Sprintf("/admin/orders/%d/status", receipt.OrderID)
+
+		api.expect(http.StatusForbidden, http.MethodPatch, statusPath, u.Token, map[string]string{"status": "paid"})
+		api.expect(http.StatusBadRequest, http.MethodPatch, statusPath, admin.Token, map[string]string{"status": "lost"})
+		api.expect(http.StatusConflict, http.MethodPatch, statusPath, admin.Token, map[string]string{"status": "delivered"})
+		api.expect(http.StatusNotFound, http.MethodPatch, "/admin/orders/999999999/status", admin.Token, map[string]string{"status": "paid"})
+		api.expect(http.StatusOK, http.MethodPatch, statusPath, admin.Token, map[string]string{"status": "paid"})
+		api.expect(http.StatusOK, http.MethodPatch, statusPath, admin.Token, map[string]string{"status": "shipped"})
+
+		// a shipped order can't be cancelled by its owner any more
+		api.expect(http.StatusConflict, http.MethodPost, fmt.Sprintf("/users/orders/%d/cancel", receipt.OrderID), u.Token, nil)
+	})
+}
@@ -4,42 +4,28 @@ import (
 	"context"
 	"fmt"
 	"log"
-	"time"
 
-	"io/ioutil"
-	"os"
-	"strings"
-
-	"github.com/joho/godotenv"
 	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgxpool"
 )
 
 var Pool *pgxpool.Pool
 
-func InitDB(){
-	// Load environment variables from .env file
-	err := godotenv.Load()
-	if err != nil {
-		log.Fatalf("Error loading .env file: %v", err)
-	}
+// settings of the current pool, kept for ResetDB
+var current Settings
 
-	// retrieve password from .env
-	password := os.Getenv("DB_PASSWORD")
-	if password == "" {
-		log.Fatalf("Environment variable PASSWORD is not set")
+// InitDB validates the settings and opens the connection pool
+func InitDB(settings Settings) error {
+	if err := settings.Validate(); err != nil {
+		return fmt.Errorf("invalid database configuration:\n%w", err)
 	}
 
 	// parse the config
-	connStr := fmt.Sprintf("postgresql://postgres.zbgohensxqhmglbyjhel:%s@aws-0-ap-southeast-1.pooler.supabase.com:6543/postgres", password)
-
-	config, err := pgxpool.ParseConfig(connStr)
-    if err != nil {
-        log.Fatalf("Failed to parsing config DB: %v", err)
-    }
+	config, err := settings.PoolConfig()
+	if err != nil {
+		return fmt.Errorf("failed to parse database config: %w", err)
+	}
 
-	config.ConnConfig.ConnectTimeout = 5 * time.Second
-	
 	// Add AfterConnect to clean up prepared statements or session state
 	config.AfterConnect = func(ctx context.Context, conn *pgx.Conn) error {
 		// Discard all session-level state, including prepared statements
@@ -49,94 +35,35 @@ func InitDB(){
 		}
 		return err
 	}
-	
+
 	// create db pooling
 	Pool, err = pgxpool.NewWithConfig(context.Background(), config)
-    if err != nil {
-        log.Fatalf("Failed to create db pooling: %v", err)
-    }
-
-    err = Pool.Ping(context.Background())
-    if err != nil {
-        log.Fatalf("DB failed Ping: %v", err)
-    }
-
-	fmt.Println("Database connected")
-}
-
-func MigrateData(){
-	// use recover to handle any potential panics
-	defer HandlePanic()
-
-	// create a context with a timeout (e.g., 30 seconds)
-	ctx, cancel := context.WithTimeout(context.Background(), 30_000_000_000)
-	defer cancel()
-
-	// read the filename from the first argument
-	filename := "config/database/ddl.sql"
-
-	// read SQL commands from the file with the given filename
-	sqlCommands, err := ReadSQLCommands(filename)
-
-	// connect to the DB
-	InitDB();
-
 	if err != nil {
-		panic(err)
+		return fmt.Errorf("failed to create db pool: %w", err)
 	}
 
-	defer CloseDB();
-
-	// execute SQL commands
-	err = ExecuteSQLCommands(ctx, Pool, sqlCommands)
-	if err != nil {
-		panic(err)
-	}
-
-	// output successful table creation and population
-	fmt.Println("All Tables Created and Populated Successfully!")
-}
-
-// func to handle panic using recover
-func HandlePanic(){
-	if r := recover(); r != nil {
-		fmt.Println("Recovered from panic", r)
-	}
-}
-
-// function to read SQL commands from a file
-func ReadSQLCommands(filename string) (string, error) {
-	data, err := ioutil.ReadFile(filename)
-	if err != nil {
-		return "", err
+	ctx, cancel := context.WithTimeout(context.Background(), settings.ConnectTimeout)
+	defer cancel()
+	if err := Pool.Ping(ctx); err != nil {
+		Pool.Close()
+		return fmt.Errorf("failed to reach database %s: %w", settings.Redacted(), err)
 	}
 
-	return string(data), nil
-}
-
-// function to execute SQL commands on the db
-func ExecuteSQLCommands(ctx context.Context, db *pgxpool.Pool, commands string) error {
-	statements := strings.Split(commands, ";") // split SQL commands by semicolon
-	for _, stmt := range statements {
-		stmt = strings.TrimSpace(stmt)		   // Trim whitespace
-		if stmt != "" {
-			_, err := db.Exec(ctx, stmt)
-			if err != nil {
-				return fmt.Errorf("failed to execute statement %q: %w", stmt, err)
-			}
-		}
-	}
+	current = settings
+	fmt.Println("Database connected")
 	return nil
 }
 
 func CloseDB() {
-    Pool.Close()
+	Pool.Close()
 }
 
 func ResetDB() {
-    if Pool != nil {
-        fmt.Println("Resetting the database connection pool...")
-        Pool.Close()
-    }
-    InitDB() // Reinitialize the pool
-}
\ No newline at end of file
+	if Pool != nil {
+		fmt.Println("Resetting the database connection pool...")
+		Pool.Close()
+	}
+	if err := InitDB(current); err != nil { // Reinitialize the pool
+		log.Fatal(err)
+	}
+}
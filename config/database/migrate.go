@@ -0,0 +1,284 @@
+package config
+
+import (
+	"context"
+	"embed"
+	"fmt"
+	"io/fs"
+	"path"
+	"regexp"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+//go:embed migrations/*.sql
+var migrationFiles embed.FS
+
+//go:embed seed.sql
+var seedSQL string
+
+// migrationLockID is the advisory lock key held while migrations run, so two deploys can't migrate at once
+const migrationLockID = 4_733_521_001
+
+// migration file names look like 0001_baseline.up.sql / 0001_baseline.down.sql
+var migrationName = regexp.MustCompile(`^(\d+)_(\w+)\.(up|down)\.sql$`)
+
+// Migration is one numbered schema change with the scripts applying and reverting it
+type Migration struct {
+	Version int
+	Name    string
+	Up      string
+	Down    string
+}
+
+// MigrationStatus tells whether a migration is applied and since when
+type MigrationStatus struct {
+	Migration
+	AppliedAt *time.Time
+}
+
+// Migrator applies the embedded migrations and records them in schema_migrations
+type Migrator struct {
+	pool       *pgxpool.Pool
+	migrations []Migration
+}
+
+// NewMigrator loads the embedded migrations, every version must have both an up and a down script
+func NewMigrator(pool *pgxpool.Pool) (*Migrator, error) {
+	migrations, err := loadMigrations(migrationFiles, "migrations")
+	if err != nil {
+		return nil, err
+	}
+	return &Migrator{pool: pool, migrations: migrations}, nil
+}
+
+func loadMigrations(fsys fs.FS, dir string) ([]Migration, error) {
+	entries, err := fs.ReadDir(fsys, dir)
+	if err != nil {
+		return nil, err
+	}
+
+	byVersion := map[int]*Migration{}
+	for _, entry := range entries {
+		m := migrationName.FindStringSubmatch(entry.Name())
+		if m == nil {
+			return nil, fmt.Errorf("migration %s: name must look like 0001_name.up.sql", entry.Name())
+		}
+		version, _ := strconv.Atoi(m[1])
+		data, err := fs.ReadFile(fsys, path.Join(dir, entry.Name()))
+		if err != nil {
+			return nil, err
+		}
+
+		mig, ok := byVersion[version]
+		if !ok {
+			mig = &Migration{Version: version, Name: m[2]}
+			byVersion[version] = mig
+		}
+		if mig.Name != m[2] {
+			return nil, fmt.Errorf("migration %d has two names: %s and %s", version, mig.Name, m[2])
+		}
+		if m[3] == "up" {
+			mig.Up = string(data)
+		} else {
+			mig.Down = string(data)
+		}
+	}
+
+	migrations := make([]Migration, 0, len(byVersion))
+	for _, mig := range byVersion {
+		if mig.Up == "" || mig.Down == "" {
+			return nil, fmt.Errorf("migration %d_%s needs both an up and a down script", mig.Version, mig.Name)
+		}
+		migrations = append(migrations, *mig)
+	}
+	sort.Slice(migrations, func(i, j int) bool { return migrations[i].Version < migrations[j].Version })
+	return migrations, nil
+}
+
+// Latest is the highest known version, 0 when there are no migrations
+func (m *Migrator) Latest() int {
+	if len(m.migrations) == 0 {
+		return 0
+	}
+	return m.migrations[len(m.migrations)-1].Version
+}
+
+// Up applies every pending migration
+func (m *Migrator) Up(ctx context.Context) error {
+	return m.To(ctx, m.Latest())
+}
+
+// Down reverts the most recently applied migration
+func (m *Migrator) Down(ctx context.Context) error {
+	_, err := m.step(ctx, func(applied map[int]time.Time) (*Migration, bool) {
+		for i := len(m.migrations) - 1; i >= 0; i-- {
+			if _, ok := applied[m.migrations[i].Version]; ok {
+				return &m.migrations[i], false
+			}
+		}
+		return nil, false
+	})
+	return err
+}
+
+// To migrates up or down until exactly the migrations up to version are applied, 0 reverts everything
+func (m *Migrator) To(ctx context.Context, version int) error {
+	if version != 0 && !m.known(version) {
+		return fmt.Errorf("unknown migration version %d", version)
+	}
+
+	for {
+		done, err := m.step(ctx, func(applied map[int]time.Time) (*Migration, bool) {
+			// revert the newest applied migration above the target first, then apply the oldest pending one
+			for i := len(m.migrations) - 1; i >= 0; i-- {
+				mig := &m.migrations[i]
+				if _, ok := applied[mig.Version]; ok && mig.Version > version {
+					return mig, false
+				}
+			}
+			for i := range m.migrations {
+				mig := &m.migrations[i]
+				if _, ok := applied[mig.Version]; !ok && mig.Version <= version {
+					return mig, true
+				}
+			}
+			return nil, false
+		})
+		if err != nil || done {
+			return err
+		}
+	}
+}
+
+// Status lists every migration with the time it was applied, if it was
+func (m *Migrator) Status(ctx context.Context) ([]MigrationStatus, error) {
+	if err := ensureTable(ctx, m.pool); err != nil {
+		return nil, err
+	}
+	applied, err := appliedVersions(ctx, m.pool)
+	if err != nil {
+		return nil, err
+	}
+
+	statuses := make([]MigrationStatus, 0, len(m.migrations))
+	for _, mig := range m.migrations {
+		status := MigrationStatus{Migration: mig}
+		if at, ok := applied[mig.Version]; ok {
+			status.AppliedAt = &at
+		}
+		statuses = append(statuses, status)
+	}
+	return statuses, nil
+}
+
+// step runs one migration chosen by pick, in its own transaction under a transaction-level advisory lock.
+// The applied versions are read after taking the lock, so concurrent runners never apply the same migration twice.
+// The lock is released on commit, which keeps it working behind a transaction-mode connection pooler.
+// It reports done when pick found nothing left to do.
+func (m *Migrator) step(ctx context.Context, pick func(applied map[int]time.Time) (mig *Migration, up bool)) (done bool, err error) {
+	tx, err := m.pool.Begin(ctx)
+	if err != nil {
+		return false, err
+	}
+	defer tx.Rollback(ctx)
+
+	if _, err := tx.Exec(ctx, "SELECT pg_advisory_xact_lock($1)", migrationLockID); err != nil {
+		return false, fmt.Errorf("taking migration lock: %w", err)
+	}
+	if err := ensureTable(ctx, tx); err != nil {
+		return false, err
+	}
+	applied, err := appliedVersions(ctx, tx)
+	if err != nil {
+		return false, err
+	}
+
+	mig, up := pick(applied)
+	if mig == nil {
+		return true, nil
+	}
+
+	script, record, args := mig.Down, "DELETE FROM schema_migrations WHERE version = $1", []any{mig.Version}
+	direction := "down"
+	if up {
+		script, record, args = mig.Up, "INSERT INTO schema_migrations (version, name) VALUES ($1, $2)", []any{mig.Version, mig.Name}
+		direction = "up"
+	}
+
+	// Exec without arguments uses the simple protocol, which runs a whole multi-statement script as is
+	if _, err := tx.Exec(ctx, script); err != nil {
+		return false, fmt.Errorf("migration %d_%s %s: %w", mig.Version, mig.Name, direction, err)
+	}
+	if _, err := tx.Exec(ctx, record, args...); err != nil {
+		return false, err
+	}
+	if err := tx.Commit(ctx); err != nil {
+		return false, err
+	}
+
+	fmt.Printf("migrated %s: %04d_%s\n", direction, mig.Version, mig.Name)
+	return false, nil
+}
+
+func (m *Migrator) known(version int) bool {
+	for _, mig := range m.migrations {
+		if mig.Version == version {
+			return true
+		}
+	}
+	return false
+}
+
+func ensureTable(ctx context.Context, db querier) error {
+	_, err := db.Exec(ctx, `CREATE TABLE IF NOT EXISTS schema_migrations (
+		version BIGINT PRIMARY KEY,
+		name TEXT NOT NULL,
+		applied_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+	)`)
+	return err
+}
+
+// querier is satisfied by both the pool and a transaction
+type querier interface {
+	Exec(ctx context.Context, sql string, args ...any) (pgconn.CommandTag, error)
+	Query(ctx context.Context, sql string, args ...any) (pgx.Rows, error)
+}
+
+func appliedVersions(ctx context.Context, db querier) (map[int]time.Time, error) {
+	rows, err := db.Query(ctx, "SELECT version, applied_at FROM schema_migrations")
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	applied := map[int]time.Time{}
+	for rows.Next() {
+		var version int
+		var at time.Time
+		if err := rows.Scan(&version, &at); err != nil {
+			return nil, err
+		}
+		applied[version] = at
+	}
+	return applied, rows.Err()
+}
+
+// Seed loads the sample data of seed.sql in one transaction, it expects freshly migrated, empty tables
+func Seed(ctx context.Context, pool *pgxpool.Pool) error {
+	tx, err := pool.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	if _, err := tx.Exec(ctx, seedSQL); err != nil {
+		return fmt.Errorf("seeding: %w", err)
+	}
+	return tx.Commit(ctx)
+}
@@ -0,0 +1,5 @@
+DROP TABLE IF EXISTS OrderItems;
+DROP TABLE IF EXISTS Orders;
+DROP TABLE IF EXISTS Carts;
+DROP TABLE IF EXISTS Products;
+DROP TABLE IF EXISTS Users;
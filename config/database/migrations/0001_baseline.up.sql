@@ -0,0 +1,45 @@
+-- The schema of the original ddl.sql. Databases created from that script already have these tables,
+-- IF NOT EXISTS lets them adopt the baseline and continue with the migrations after it.
+
+-- Create Users table
+CREATE TABLE IF NOT EXISTS Users (
+    user_id SERIAL PRIMARY KEY,
+    name VARCHAR(100),
+    email VARCHAR(100) UNIQUE NOT NULL,
+    password VARCHAR(255) NOT NULL,
+    jwt_token VARCHAR(255)
+);
+
+-- Create Products table
+CREATE TABLE IF NOT EXISTS Products (
+    product_id SERIAL PRIMARY KEY,
+    name VARCHAR(100),
+    description TEXT,
+    price DECIMAL(10, 2)
+);
+
+-- Create Carts table, which contains user_id and product_id as foreign keys
+CREATE TABLE IF NOT EXISTS Carts (
+    cart_id SERIAL PRIMARY KEY,
+    user_id INTEGER REFERENCES Users(user_id),
+    product_id INTEGER REFERENCES Products(product_id),
+    quantity INTEGER,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+-- Create Orders table, with a foreign key reference to Users
+CREATE TABLE IF NOT EXISTS Orders (
+    order_id SERIAL PRIMARY KEY,
+    user_id INTEGER REFERENCES Users(user_id),
+    total_price DECIMAL(10,2),
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+-- Create OrderItems table to record individual items in each order, with foreign key references to Orders and Products
+CREATE TABLE IF NOT EXISTS OrderItems (
+    order_item_id SERIAL PRIMARY KEY,
+    order_id INTEGER REFERENCES Orders(order_id),
+    product_id INTEGER REFERENCES Products(product_id),
+    quantity INTEGER,
+    price DECIMAL(10,2)
+);
@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS OrderStatusHistory;
+ALTER TABLE Orders DROP COLUMN IF EXISTS status;
@@ -0,0 +1,14 @@
+-- Orders move through a status lifecycle, existing orders start as pending
+ALTER TABLE Orders
+    ADD COLUMN status VARCHAR(20) NOT NULL DEFAULT 'pending'
+        CHECK (status IN ('pending', 'paid', 'shipped', 'delivered', 'cancelled'));
+
+-- Create OrderStatusHistory table to record every status change of an order and who made it
+CREATE TABLE OrderStatusHistory (
+    history_id SERIAL PRIMARY KEY,
+    order_id INTEGER NOT NULL REFERENCES Orders(order_id),
+    from_status VARCHAR(20),
+    to_status VARCHAR(20) NOT NULL,
+    changed_by INTEGER REFERENCES Users(user_id),
+    changed_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS StockAdjustments;
+ALTER TABLE Products DROP COLUMN IF EXISTS stock;
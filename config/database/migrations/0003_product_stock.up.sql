@@ -0,0 +1,15 @@
+-- Products keep a stock that checkout reserves, existing products start out of stock
+ALTER TABLE Products
+    ADD COLUMN stock INTEGER NOT NULL DEFAULT 0 CHECK (stock >= 0);
+
+-- Create StockAdjustments table to audit manual stock changes made by admins
+CREATE TABLE StockAdjustments (
+    adjustment_id SERIAL PRIMARY KEY,
+    product_id INTEGER NOT NULL REFERENCES Products(product_id),
+    delta INTEGER NOT NULL,
+    reason VARCHAR(20) NOT NULL
+        CHECK (reason IN ('restock', 'damaged', 'lost', 'returned', 'correction')),
+    note TEXT,
+    adjusted_by INTEGER REFERENCES Users(user_id),
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
@@ -0,0 +1,2 @@
+ALTER TABLE Products DROP COLUMN IF EXISTS deleted_at;
+ALTER TABLE Users DROP COLUMN IF EXISTS role;
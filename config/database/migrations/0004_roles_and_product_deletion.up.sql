@@ -0,0 +1,7 @@
+-- Every account is a customer unless made an admin
+ALTER TABLE Users
+    ADD COLUMN role VARCHAR(20) NOT NULL DEFAULT 'customer'
+        CHECK (role IN ('customer', 'admin'));
+
+-- Products are soft deleted so past orders keep pointing at them
+ALTER TABLE Products ADD COLUMN deleted_at TIMESTAMP;
@@ -0,0 +1,3 @@
+DROP INDEX IF EXISTS idx_products_created_at;
+DROP INDEX IF EXISTS idx_products_price;
+ALTER TABLE Products DROP COLUMN IF EXISTS created_at;
@@ -0,0 +1,6 @@
+-- Existing products get the time of the migration as creation time
+ALTER TABLE Products ADD COLUMN created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP;
+
+-- Indexes backing the sort options of GET /products
+CREATE INDEX idx_products_price ON Products (price, product_id);
+CREATE INDEX idx_products_created_at ON Products (created_at, product_id);
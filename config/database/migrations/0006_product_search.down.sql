@@ -0,0 +1,2 @@
+DROP INDEX IF EXISTS idx_products_search;
+ALTER TABLE Products DROP COLUMN IF EXISTS search_vector;
@@ -0,0 +1,9 @@
+-- Names weigh more than descriptions in search results
+ALTER TABLE Products
+    ADD COLUMN search_vector TSVECTOR GENERATED ALWAYS AS (
+        setweight(to_tsvector('english', COALESCE(name, '')), 'A') ||
+        setweight(to_tsvector('english', COALESCE(description, '')), 'B')
+    ) STORED;
+
+-- Full-text index backing GET /products/search
+CREATE INDEX idx_products_search ON Products USING GIN (search_vector);
@@ -0,0 +1,19 @@
+-- Create Categories table, parent_id nests a category under another one
+CREATE TABLE Categories (
+    category_id SERIAL PRIMARY KEY,
+    name VARCHAR(100) NOT NULL,
+    slug VARCHAR(100) UNIQUE NOT NULL,
+    parent_id INTEGER REFERENCES Categories(category_id),
+    CHECK (parent_id IS NULL OR parent_id <> category_id)
+);
+
+CREATE INDEX idx_categories_parent ON Categories (parent_id);
+
+-- Create ProductCategories table linking products to any number of categories
+CREATE TABLE ProductCategories (
+    product_id INTEGER NOT NULL REFERENCES Products(product_id),
+    category_id INTEGER NOT NULL REFERENCES Categories(category_id),
+    PRIMARY KEY (product_id, category_id)
+);
+
+CREATE INDEX idx_product_categories_category ON ProductCategories (category_id);
@@ -0,0 +1,3 @@
+ALTER TABLE Carts
+    DROP CONSTRAINT IF EXISTS carts_user_id_product_id_key,
+    DROP CONSTRAINT IF EXISTS carts_quantity_check;
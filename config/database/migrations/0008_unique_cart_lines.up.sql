@@ -0,0 +1,22 @@
+-- Merge duplicate lines of a product into the oldest one and drop empty lines before the constraints apply
+UPDATE Carts c
+SET quantity = d.total
+FROM (
+    SELECT MIN(cart_id) AS cart_id, SUM(quantity) AS total
+    FROM Carts
+    WHERE quantity > 0
+    GROUP BY user_id, product_id
+) d
+WHERE c.cart_id = d.cart_id;
+
+DELETE FROM Carts c
+WHERE c.quantity IS NULL OR c.quantity <= 0
+   OR EXISTS (
+       SELECT 1 FROM Carts o
+       WHERE o.user_id = c.user_id AND o.product_id = c.product_id AND o.cart_id < c.cart_id AND o.quantity > 0
+   );
+
+-- One row per product per user
+ALTER TABLE Carts
+    ADD CHECK (quantity > 0),
+    ADD UNIQUE (user_id, product_id);
@@ -0,0 +1,3 @@
+DROP TABLE IF EXISTS RevokedTokens;
+DROP TABLE IF EXISTS RefreshTokens;
+ALTER TABLE Users ADD COLUMN IF NOT EXISTS jwt_token VARCHAR(255);
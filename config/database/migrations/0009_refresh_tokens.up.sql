@@ -0,0 +1,24 @@
+-- Sessions live in RefreshTokens now, the token column of the original schema is unused
+ALTER TABLE Users DROP COLUMN IF EXISTS jwt_token;
+
+-- Create RefreshTokens table, only the SHA-256 hash of a refresh token is stored.
+-- Tokens rotated from the same login share a family_id so a reused token can revoke them all.
+CREATE TABLE RefreshTokens (
+    token_id SERIAL PRIMARY KEY,
+    user_id INTEGER NOT NULL REFERENCES Users(user_id),
+    family_id VARCHAR(32) NOT NULL,
+    token_hash CHAR(64) UNIQUE NOT NULL,
+    expires_at TIMESTAMP NOT NULL,
+    used_at TIMESTAMP,
+    revoked_at TIMESTAMP,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX idx_refresh_tokens_family ON RefreshTokens (family_id);
+CREATE INDEX idx_refresh_tokens_user ON RefreshTokens (user_id);
+
+-- Create RevokedTokens table listing access tokens (by jti) that were logged out before they expired
+CREATE TABLE RevokedTokens (
+    jti VARCHAR(64) PRIMARY KEY,
+    expires_at TIMESTAMP NOT NULL
+);
@@ -0,0 +1,11 @@
+-- Create PasswordResetTokens table, tokens are single-use and stored as SHA-256 hashes
+CREATE TABLE PasswordResetTokens (
+    token_id SERIAL PRIMARY KEY,
+    user_id INTEGER NOT NULL REFERENCES Users(user_id),
+    token_hash CHAR(64) UNIQUE NOT NULL,
+    expires_at TIMESTAMP NOT NULL,
+    used_at TIMESTAMP,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX idx_password_reset_tokens_user ON PasswordResetTokens (user_id);
@@ -0,0 +1,6 @@
+ALTER TABLE Users
+    ADD COLUMN email_verified_at TIMESTAMP,
+    ADD COLUMN verification_sent_at TIMESTAMP;
+
+-- Accounts created before verification existed keep being able to check out
+UPDATE Users SET email_verified_at = CURRENT_TIMESTAMP;
@@ -0,0 +1,2 @@
+-- Deleted accounts are kept for the orders pointing at them
+ALTER TABLE Users ADD COLUMN deleted_at TIMESTAMP;
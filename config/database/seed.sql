@@ -0,0 +1,59 @@
+-- Sample data for local development, loaded on an empty database after the migrations
+
+-- Insert sample data into Users table, both customers log in with the password secret123.
+-- Admins are created with: user create --admin
+INSERT INTO Users (name, email, password, role, email_verified_at) 
+VALUES 
+('Alice Johnson', 'alice.johnson@example.com', '$2a$10$A8tI4O4wo5JniXUTYwYZ1.HxvuqT0WZqqOAgD61S8QgH/xXDpQhXS', 'customer', CURRENT_TIMESTAMP),
+('Bob Smith', 'bob.smith@example.com', '$2a$10$RSraiy1m.vqh2Uz1FJC8O.3ZVusW.Z4oTF57EWQ7QCkQ3bzUtsune', 'customer', CURRENT_TIMESTAMP);
+
+-- Insert sample data into Products table
+INSERT INTO Products (name, description, price, stock) 
+VALUES 
+('Product1', 'Product1 Description', 100.00, 50),
+('Product2', 'Product2 Description', 200.00, 20),
+('Product3', 'Product3 Description', 300.00, 10);
+
+-- Insert sample data into Categories table
+INSERT INTO Categories (name, slug, parent_id) 
+VALUES 
+('Electronics', 'electronics', NULL),
+('Phones', 'phones', 1),
+('Laptops', 'laptops', 1),
+('Home', 'home', NULL);
+
+-- Insert sample data into ProductCategories table
+INSERT INTO ProductCategories (product_id, category_id) 
+VALUES 
+(1, 2),
+(2, 3),
+(3, 4);
+
+-- Insert sample data into Carts table
+INSERT INTO Carts (user_id, product_id, quantity, created_at) 
+VALUES 
+(1, 1, 2, '2023-09-09 10:00:00'),
+(1, 2, 1, '2023-09-09 10:05:00'),
+(2, 3, 3, '2023-09-09 10:10:00');
+
+-- Insert sample data into Orders table
+INSERT INTO Orders (user_id, total_price, status, created_at) 
+VALUES 
+(1, 300.00, 'delivered', '2023-09-10 11:00:00'),
+(2, 900.00, 'pending', '2023-09-10 11:05:00');
+
+-- Insert sample data into OrderItems table
+INSERT INTO OrderItems (order_id, product_id, quantity, price) 
+VALUES 
+(1, 1, 2, 100.00),
+(1, 2, 1, 200.00),
+(2, 3, 3, 300.00);
+
+-- Insert sample data into OrderStatusHistory table
+INSERT INTO OrderStatusHistory (order_id, from_status, to_status, changed_by, changed_at) 
+VALUES 
+(1, NULL, 'pending', 1, '2023-09-10 11:00:00'),
+(1, 'pending', 'paid', 1, '2023-09-10 11:30:00'),
+(1, 'paid', 'shipped', NULL, '2023-09-11 09:00:00'),
+(1, 'shipped', 'delivered', NULL, '2023-09-12 15:00:00'),
+(2, NULL, 'pending', 2, '2023-09-10 11:05:00');
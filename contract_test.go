@@ -0,0 +1,331 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"os"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"testing"
+
+	"gopkg.in/yaml.v3"
+)
+
+// contract is an API description responses are checked against.
+// docs/swagger.yaml is generated from the handlers and describes every route exactly, so it is strict:
+// every status must be documented and undocumented properties are errors. docs.yaml is the original
+// contract of the README routes; the API has grown past it, so only the statuses it documents are
+// checked and new properties are allowed, but every documented property must be there. Where a
+// response deliberately departs from it, the departure is listed in diverges.
+type contract struct {
+	name     string
+	doc      map[string]any
+	strict   bool
+	paramFmt func(name string) string // how a path parameter is written in the path keys
+	diverges map[string]divergence    // "METHOD route status" -> how the response departs from the contract
+}
+
+// divergence describes how a response departs from its documented body, everything else is still checked
+type divergence struct {
+	under  string            // the documented body is the value of this property of the response
+	single bool              // the response is one item of the documented array
+	rename map[string]string // documented property -> the property of the response carrying it
+	omit   []string          // documented properties the response doesn't carry
+	why    string
+}
+
+var (
+	swaggerContract = loadContract("docs/swagger.yaml", true, func(name string) string { return "{" + name + "}" }, nil)
+	readmeContract  = loadContract("docs.yaml", false, func(name string) string { return ":" + name }, map[string]divergence{
+		"POST /users/login 200": {
+			omit: []string{"message"},
+			why:  "login returns a token pair, the refresh token and expiry took the place of the message",
+		},
+		"GET /users/carts 200": {
+			under:  "cart",
+			rename: map[string]string{"id": "cart_id", "price": "unit_price"},
+			why:    "the lines sit next to a price summary, and a line names its own id and the unit price apart from the product's",
+		},
+		"GET /users/orders 200": {
+			under:  "orders",
+			rename: map[string]string{"id": "order_id"},
+			omit:   []string{"items"},
+			why:    "the list only has the order headers, the items come with GET /users/orders/:id",
+		},
+		"GET /products 200": {
+			under:  "products",
+			rename: map[string]string{"id": "product_id"},
+			why:    "the list is paginated, its metadata sits next to the products",
+		},
+		"GET /products/:id 200": {
+			single: true,
+			rename: map[string]string{"id": "product_id"},
+			why:    "the route returns the one product it names instead of a list holding it",
+		},
+	})
+)
+
+func loadContract(path string, strict bool, paramFmt func(string) string, diverges map[string]divergence) *contract {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		panic(err)
+	}
+	var doc any
+	if err := yaml.Unmarshal(data, &doc); err != nil {
+		panic(fmt.Sprintf("parsing %s: %v", path, err))
+	}
+	return &contract{name: path, doc: normalize(doc).(map[string]any), strict: strict, paramFmt: paramFmt, diverges: diverges}
+}
+
+// normalize turns the maps decoded from YAML into map[string]any, status codes are integer keys in docs.yaml
+func normalize(v any) any {
+	switch v := v.(type) {
+	case map[string]any:
+		for k, item := range v {
+			v[k] = normalize(item)
+		}
+		return v
+	case map[any]any:
+		m := make(map[string]any, len(v))
+		for k, item := range v {
+			m[fmt.Sprint(k)] = normalize(item)
+		}
+		return m
+	case []any:
+		for i, item := range v {
+			v[i] = normalize(item)
+		}
+		return v
+	}
+	return v
+}
+
+var routeParam = regexp.MustCompile(`:(\w+)`)
+
+// check validates a response of the route, an echo pattern such as /users/carts/:id
+func (ct *contract) check(t *testing.T, method, route string, status int, body []byte) {
+	t.Helper()
+	key := fmt.Sprintf("%s %s %d", method, route, status)
+
+	path := routeParam.ReplaceAllStringFunc(route, func(m string) string { return ct.paramFmt(m[1:]) })
+	op, ok := lookup(ct.doc, "paths", path, strings.ToLower(method))
+	if !ok {
+		if ct.strict {
+			t.Errorf("%s: %s %s is not documented", ct.name, method, route)
+		}
+		return
+	}
+	resp, ok := lookup(op, "responses", strconv.Itoa(status))
+	if !ok {
+		if ct.strict {
+			t.Errorf("%s: %s %s does not document status %d", ct.name, method, route, status)
+		}
+		return
+	}
+	resp = ct.resolve(resp)
+
+	// swagger 2 puts the schema on the response, OpenAPI 3 under its content type
+	schema, ok := lookup(resp, "schema")
+	if !ok {
+		schema, ok = lookup(resp, "content", "application/json", "schema")
+	}
+	if !ok {
+		if len(strings.TrimSpace(string(body))) > 0 && ct.strict {
+			t.Errorf("%s: %s returned a body but none is documented", ct.name, key)
+		}
+		return
+	}
+
+	var value any
+	if err := json.Unmarshal(body, &value); err != nil {
+		t.Errorf("%s: %s: body is not JSON: %v", ct.name, key, err)
+		return
+	}
+	at := "$"
+	if d, ok := ct.diverges[key]; ok {
+		var problems []string
+		if schema, value, at, problems = d.apply(ct.resolve(schema).(map[string]any), value); problems != nil {
+			for _, problem := range problems {
+				t.Errorf("%s: %s: %s", ct.name, key, problem)
+			}
+			return
+		}
+	}
+	for _, problem := range ct.validate(schema.(map[string]any), value, at) {
+		t.Errorf("%s: %s: %s", ct.name, key, problem)
+	}
+}
+
+// apply returns the part of the documented body the response still follows and the response adapted to it:
+// renamed properties are copied to their documented names and omitted ones are set to null, which validate accepts
+func (d divergence) apply(schema map[string]any, value any) (map[string]any, any, string, []string) {
+	at := "$"
+	if d.under != "" {
+		obj, ok := value.(map[string]any)
+		if !ok {
+			return nil, nil, at, []string{fmt.Sprintf("%s: want an object holding %s, got %T", at, d.under, value)}
+		}
+		if value, ok = obj[d.under]; !ok {
+			return nil, nil, at, []string{fmt.Sprintf("%s: missing property %s", at, d.under)}
+		}
+		at += "." + d.under
+	}
+	if d.single {
+		items, ok := schema["items"].(map[string]any)
+		if !ok {
+			return nil, nil, at, []string{"the documented body is not an array"}
+		}
+		schema = items
+	}
+
+	var problems []string
+	var adapt func(v any, at string)
+	adapt = func(v any, at string) {
+		switch v := v.(type) {
+		case []any:
+			for i, item := range v {
+				adapt(item, fmt.Sprintf("%s[%d]", at, i))
+			}
+		case map[string]any:
+			for documented, actual := range d.rename {
+				if _, ok := v[actual]; !ok {
+					problems = append(problems, fmt.Sprintf("%s: missing property %s standing in for %s", at, actual, documented))
+				}
+				v[documented] = v[actual]
+			}
+			for _, name := range d.omit {
+				v[name] = nil
+			}
+		}
+	}
+	adapt(value, at)
+	return schema, value, at, problems
+}
+
+// validate returns what is wrong with value according to schema
+func (ct *contract) validate(schema map[string]any, value any, at string) []string {
+	schema = ct.resolve(schema).(map[string]any)
+	if value == nil {
+		return nil // swagger 2 can't mark fields nullable, pointers show up as null
+	}
+
+	var problems []string
+	if all, ok := schema["allOf"].([]any); ok {
+		for _, part := range all {
+			problems = append(problems, ct.validate(part.(map[string]any), value, at)...)
+		}
+	}
+
+	typ, _ := schema["type"].(string)
+	if typ == "" && schema["properties"] != nil {
+		typ = "object"
+	}
+	switch typ {
+	case "object":
+		obj, ok := value.(map[string]any)
+		if !ok {
+			return append(problems, fmt.Sprintf("%s: want an object, got %T", at, value))
+		}
+		props, _ := schema["properties"].(map[string]any)
+		required, _ := schema["required"].([]any)
+		if !ct.strict {
+			// a loose contract predates the required lists, everything it documents is expected
+			required = nil
+			for name := range props {
+				required = append(required, name)
+			}
+			sort.Slice(required, func(i, j int) bool { return required[i].(string) < required[j].(string) })
+		}
+		for _, name := range required {
+			if _, ok := obj[name.(string)]; !ok {
+				problems = append(problems, fmt.Sprintf("%s: missing required property %s", at, name))
+			}
+		}
+		keys := make([]string, 0, len(obj))
+		for k := range obj {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		for _, k := range keys {
+			if prop, ok := props[k]; ok {
+				problems = append(problems, ct.validate(prop.(map[string]any), obj[k], at+"."+k)...)
+				continue
+			}
+			switch extra := schema["additionalProperties"].(type) {
+			case map[string]any:
+				problems = append(problems, ct.validate(extra, obj[k], at+"."+k)...)
+			case bool:
+				if !extra {
+					problems = append(problems, fmt.Sprintf("%s: undocumented property %s", at, k))
+				}
+			case nil:
+				if ct.strict && props != nil {
+					problems = append(problems, fmt.Sprintf("%s: undocumented property %s", at, k))
+				}
+			}
+		}
+	case "array":
+		list, ok := value.([]any)
+		if !ok {
+			return append(problems, fmt.Sprintf("%s: want an array, got %T", at, value))
+		}
+		if items, ok := schema["items"].(map[string]any); ok {
+			for i, item := range list {
+				problems = append(problems, ct.validate(items, item, fmt.Sprintf("%s[%d]", at, i))...)
+			}
+		}
+	case "string":
+		if _, ok := value.(string); !ok {
+			problems = append(problems, fmt.Sprintf("%s: want a string, got %T", at, value))
+		}
+	case "number":
+		if _, ok := value.(float64); !ok {
+			problems = append(problems, fmt.Sprintf("%s: want a number, got %T", at, value))
+		}
+	case "integer":
+		if n, ok := value.(float64); !ok || n != math.Trunc(n) {
+			problems = append(problems, fmt.Sprintf("%s: want an integer, got %v", at, value))
+		}
+	case "boolean":
+		if _, ok := value.(bool); !ok {
+			problems = append(problems, fmt.Sprintf("%s: want a boolean, got %T", at, value))
+		}
+	}
+	return problems
+}
+
+// resolve follows a local $ref such as #/definitions/handler.Product
+func (ct *contract) resolve(node any) any {
+	for {
+		m, ok := node.(map[string]any)
+		if !ok {
+			return node
+		}
+		ref, ok := m["$ref"].(string)
+		if !ok {
+			return node
+		}
+		target, ok := lookup(ct.doc, strings.Split(strings.TrimPrefix(ref, "#/"), "/")...)
+		if !ok {
+			panic(fmt.Sprintf("%s: unresolved reference %s", ct.name, ref))
+		}
+		node = target
+	}
+}
+
+// lookup walks nested maps by key
+func lookup(node any, keys ...string) (any, bool) {
+	for _, k := range keys {
+		m, ok := node.(map[string]any)
+		if !ok {
+			return nil, false
+		}
+		if node, ok = m[k]; !ok {
+			return nil, false
+		}
+	}
+	return node, true
+}
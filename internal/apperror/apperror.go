@@ -0,0 +1,125 @@
+package apperror
+
+import (
+	"fmt"
+	"net/http"
+)
+
+// Code is a stable, machine-readable error identifier returned in every error response
+type Code string
+
+// Generic codes, one per HTTP status the API returns
+const (
+	CodeBadRequest      Code = "BAD_REQUEST"
+	CodeValidation      Code = "VALIDATION_FAILED"
+	CodeUnauthorized    Code = "UNAUTHORIZED"
+	CodeForbidden       Code = "FORBIDDEN"
+	CodeNotFound        Code = "NOT_FOUND"
+	CodeConflict        Code = "CONFLICT"
+	CodeTooManyRequests Code = "TOO_MANY_REQUESTS"
+	CodeInternal        Code = "INTERNAL_ERROR"
+)
+
+// Domain codes, used when a client may want to react to one specific failure
+const (
+	CodeInvalidCredentials  Code = "INVALID_CREDENTIALS"
+	CodeInvalidRefreshToken Code = "INVALID_REFRESH_TOKEN"
+	CodeTokenRevoked        Code = "TOKEN_REVOKED"
+	CodeInvalidResetToken   Code = "INVALID_RESET_TOKEN"
+	CodeInvalidVerifyToken  Code = "INVALID_VERIFICATION_TOKEN"
+	CodeEmailNotVerified    Code = "EMAIL_NOT_VERIFIED"
+	CodeAlreadyVerified     Code = "EMAIL_ALREADY_VERIFIED"
+	CodeEmailTaken          Code = "EMAIL_TAKEN"
+	CodeCartEmpty           Code = "CART_EMPTY"
+	CodeInsufficientStock   Code = "INSUFFICIENT_STOCK"
+	CodeProductUnavailable  Code = "PRODUCT_UNAVAILABLE"
+	CodeInvalidTransition   Code = "INVALID_STATUS_TRANSITION"
+)
+
+// Error is a domain error carrying the HTTP status and code it maps to.
+// Handlers return it and the HTTP error handler renders it.
+type Error struct {
+	Status  int          `json:"-"`
+	Code    Code         `json:"code"`
+	Message string       `json:"message"`
+	Fields  []FieldError `json:"errors,omitempty"`
+	Err     error        `json:"-"` // the cause, logged by the HTTP error handler but never sent to the client
+}
+
+// FieldError describes one invalid field of a request body
+type FieldError struct {
+	Field   string `json:"field"`
+	Rule    string `json:"rule"`
+	Message string `json:"message"`
+}
+
+func (e *Error) Error() string {
+	if e.Err != nil {
+		return fmt.Sprintf("%s: %s: %v", e.Code, e.Message, e.Err)
+	}
+	return fmt.Sprintf("%s: %s", e.Code, e.Message)
+}
+
+func (e *Error) Unwrap() error {
+	return e.Err
+}
+
+// WithCode returns a copy of the error with a more specific code
+func (e *Error) WithCode(code Code) *Error {
+	clone := *e
+	clone.Code = code
+	return &clone
+}
+
+// New creates an error with the given status, code and message
+func New(status int, code Code, message string) *Error {
+	return &Error{Status: status, Code: code, Message: message}
+}
+
+// BadRequest is returned for malformed or invalid input
+func BadRequest(message string) *Error {
+	return New(http.StatusBadRequest, CodeBadRequest, message)
+}
+
+// Validation is returned when the request body breaks one or more validation rules
+func Validation(fields []FieldError) *Error {
+	e := New(http.StatusBadRequest, CodeValidation, "Validation failed")
+	if len(fields) > 0 {
+		e.Message = fields[0].Message
+	}
+	e.Fields = fields
+	return e
+}
+
+// Unauthorized is returned when the access token is missing or invalid
+func Unauthorized(message string) *Error {
+	return New(http.StatusUnauthorized, CodeUnauthorized, message)
+}
+
+// Forbidden is returned when the user is authenticated but not allowed to perform the action
+func Forbidden(message string) *Error {
+	return New(http.StatusForbidden, CodeForbidden, message)
+}
+
+// NotFound is returned when the requested resource does not exist or is not visible to the user
+func NotFound(message string) *Error {
+	return New(http.StatusNotFound, CodeNotFound, message)
+}
+
+// Conflict is returned when the request clashes with the current state of a resource
+func Conflict(message string) *Error {
+	return New(http.StatusConflict, CodeConflict, message)
+}
+
+// TooManyRequests is returned when the user has to wait before repeating the action
+func TooManyRequests(message string) *Error {
+	return New(http.StatusTooManyRequests, CodeTooManyRequests, message)
+}
+
+// Internal wraps an unexpected error. Only the message reaches the client, err names tables,
+// constraints and hosts and is only written to the server log.
+func Internal(message string, err error) *Error {
+	e := New(http.StatusInternalServerError, CodeInternal, message)
+	e.Err = err
+	return e
+}
@@ -0,0 +1,70 @@
+package apperror
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/labstack/echo/v4"
+)
+
+// codes used for errors raised by echo itself, e.g. unknown routes or bind failures
+var statusCodes = map[int]Code{
+	http.StatusBadRequest:      CodeBadRequest,
+	http.StatusUnauthorized:    CodeUnauthorized,
+	http.StatusForbidden:       CodeForbidden,
+	http.StatusNotFound:        CodeNotFound,
+	http.StatusConflict:        CodeConflict,
+	http.StatusTooManyRequests: CodeTooManyRequests,
+}
+
+// HTTPErrorHandler renders every error returned by a handler or middleware as
+// {"code": ..., "message": ...}, the cause of internal errors is logged instead.
+// Install it with e.HTTPErrorHandler = apperror.HTTPErrorHandler.
+func HTTPErrorHandler(err error, c echo.Context) {
+	if c.Response().Committed {
+		return
+	}
+
+	appErr := From(err)
+	if appErr.Status >= http.StatusInternalServerError {
+		c.Logger().Error(err)
+	}
+
+	if c.Request().Method == http.MethodHead {
+		err = c.NoContent(appErr.Status)
+	} else {
+		err = c.JSON(appErr.Status, appErr)
+	}
+	if err != nil {
+		c.Logger().Error(err)
+	}
+}
+
+// From converts any error into an *Error, unknown errors become internal errors
+func From(err error) *Error {
+	var appErr *Error
+	if errors.As(err, &appErr) {
+		return appErr
+	}
+
+	var httpErr *echo.HTTPError
+	if errors.As(err, &httpErr) {
+		message := http.StatusText(httpErr.Code)
+		if m, ok := httpErr.Message.(string); ok {
+			message = m
+		} else if httpErr.Message != nil {
+			message = fmt.Sprint(httpErr.Message)
+		}
+		if httpErr.Code >= http.StatusInternalServerError {
+			return Internal(message, httpErr.Internal)
+		}
+		code, ok := statusCodes[httpErr.Code]
+		if !ok {
+			code = Code(fmt.Sprintf("HTTP_%d", httpErr.Code))
+		}
+		return New(httpErr.Code, code, message)
+	}
+
+	return Internal("Internal Server Error", err)
+}
@@ -0,0 +1,157 @@
+package auth
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/golang-jwt/jwt/v4"
+)
+
+// minSecretLength is the shortest HMAC secret accepted, HS256 needs at least 256 bits of key
+const minSecretLength = 32
+
+// Key is one signing key, identified in tokens by the kid header.
+// HMAC keys sign and verify with the same secret, RSA and Ed25519 keys verify with their public half.
+type Key struct {
+	ID        string
+	Method    jwt.SigningMethod
+	signKey   interface{}
+	verifyKey interface{}
+}
+
+// KeySet holds every key tokens may be verified with and the one new tokens are signed with.
+// Rotating means adding a new key, making it current and keeping the old one until its tokens expire.
+type KeySet struct {
+	current string
+	keys    map[string]Key
+}
+
+// keyFile is the JSON layout of JWT_KEYS_FILE
+type keyFile struct {
+	Current string            `json:"current"`
+	Keys    map[string]string `json:"keys"`
+}
+
+// Keys is the key set used by the API, set by Init
+var Keys *KeySet
+
+// Init loads the key set from the environment and fails hard when it is missing or invalid
+func Init() error {
+	ks, err := Load()
+	if err != nil {
+		return err
+	}
+	Keys = ks
+	return nil
+}
+
+// Load reads the keys from the PEM files in JWT_KEY_DIR, the JSON file at JWT_KEYS_FILE, or else from JWT_KEYS
+// ("kid=secret,kid=secret") with JWT_CURRENT_KID naming the signing key (the first one by default)
+func Load() (*KeySet, error) {
+	if dir := os.Getenv("JWT_KEY_DIR"); dir != "" {
+		return LoadDir(dir, os.Getenv("JWT_CURRENT_KID"), os.Getenv("JWT_ALG"))
+	}
+
+	if path := os.Getenv("JWT_KEYS_FILE"); path != "" {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("reading JWT_KEYS_FILE: %w", err)
+		}
+		var f keyFile
+		if err := json.Unmarshal(data, &f); err != nil {
+			return nil, fmt.Errorf("parsing JWT_KEYS_FILE: %w", err)
+		}
+		return NewHMACKeySet(f.Current, f.Keys)
+	}
+
+	raw := os.Getenv("JWT_KEYS")
+	if raw == "" {
+		return nil, errors.New("no JWT signing keys configured, set JWT_KEY_DIR, JWT_KEYS_FILE or JWT_KEYS")
+	}
+	secrets := map[string]string{}
+	current := os.Getenv("JWT_CURRENT_KID")
+	for _, entry := range strings.Split(raw, ",") {
+		kid, secret, ok := strings.Cut(strings.TrimSpace(entry), "=")
+		if !ok {
+			return nil, fmt.Errorf("JWT_KEYS entry %q must look like kid=secret", kid)
+		}
+		if _, dup := secrets[kid]; dup {
+			return nil, fmt.Errorf("JWT_KEYS has kid %q more than once", kid)
+		}
+		secrets[kid] = secret
+		if current == "" {
+			current = kid
+		}
+	}
+	return NewHMACKeySet(current, secrets)
+}
+
+// NewHMACKeySet validates the secrets and builds an HS256 key set signing with current
+func NewHMACKeySet(current string, secrets map[string]string) (*KeySet, error) {
+	var keys []Key
+	for kid, secret := range secrets {
+		if len(secret) < minSecretLength {
+			return nil, fmt.Errorf("JWT key %q must be at least %d bytes long", kid, minSecretLength)
+		}
+		keys = append(keys, Key{ID: kid, Method: jwt.SigningMethodHS256, signKey: []byte(secret), verifyKey: []byte(secret)})
+	}
+	return NewKeySet(current, keys)
+}
+
+// NewKeySet builds a key set signing with current
+func NewKeySet(current string, keys []Key) (*KeySet, error) {
+	ks := &KeySet{current: current, keys: map[string]Key{}}
+	for _, key := range keys {
+		if key.ID == "" {
+			return nil, errors.New("JWT key id must not be empty")
+		}
+		ks.keys[key.ID] = key
+	}
+	if _, ok := ks.keys[current]; !ok {
+		return nil, fmt.Errorf("current JWT key %q is not in the key set", current)
+	}
+	return ks, nil
+}
+
+// Sign signs the claims with the current key and sets its kid header
+func (ks *KeySet) Sign(claims jwt.Claims) (string, error) {
+	key := ks.keys[ks.current]
+	token := jwt.NewWithClaims(key.Method, claims)
+	token.Header["kid"] = key.ID
+	return token.SignedString(key.signKey)
+}
+
+// Parse verifies a token against the key named by its kid header.
+// The token's alg must be the algorithm of that key, so a public key can never be used as an HMAC secret.
+func (ks *KeySet) Parse(tokenString string, claims jwt.Claims) (*jwt.Token, error) {
+	parser := jwt.NewParser(jwt.WithValidMethods(ks.algorithms()))
+	return parser.ParseWithClaims(tokenString, claims, ks.keyFunc)
+}
+
+func (ks *KeySet) keyFunc(token *jwt.Token) (interface{}, error) {
+	kid, _ := token.Header["kid"].(string)
+	key, ok := ks.keys[kid]
+	if !ok {
+		return nil, fmt.Errorf("unknown signing key %q", kid)
+	}
+	if token.Method.Alg() != key.Method.Alg() {
+		return nil, fmt.Errorf("unexpected signing method: %v", token.Header["alg"])
+	}
+	return key.verifyKey, nil
+}
+
+// algorithms lists the algorithms of the keys in the set
+func (ks *KeySet) algorithms() []string {
+	var algs []string
+	seen := map[string]bool{}
+	for _, key := range ks.keys {
+		if alg := key.Method.Alg(); !seen[alg] {
+			seen[alg] = true
+			algs = append(algs, alg)
+		}
+	}
+	return algs
+}
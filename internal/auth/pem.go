@@ -0,0 +1,148 @@
+package auth
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/golang-jwt/jwt/v4"
+)
+
+const (
+	rsaKeyBits    = 2048
+	pemExtension  = ".pem"
+	defaultKeyAlg = "RS256"
+)
+
+// LoadDir loads every <kid>.pem private key in dir. When the current key is missing it is generated
+// with alg (RS256 or EdDSA) and written to the directory, so a fresh deployment starts with one key.
+// Without a current kid the lexically greatest kid signs, which makes date-based kids rotate naturally.
+func LoadDir(dir, current, alg string) (*KeySet, error) {
+	if err := os.MkdirAll(dir, 0o700); err != nil {
+		return nil, fmt.Errorf("creating JWT_KEY_DIR: %w", err)
+	}
+	paths, err := filepath.Glob(filepath.Join(dir, "*"+pemExtension))
+	if err != nil {
+		return nil, err
+	}
+
+	var keys []Key
+	for _, path := range paths {
+		key, err := readPrivateKey(path)
+		if err != nil {
+			return nil, err
+		}
+		keys = append(keys, key)
+	}
+	sort.Slice(keys, func(i, j int) bool { return keys[i].ID < keys[j].ID })
+
+	if current == "" && len(keys) > 0 {
+		current = keys[len(keys)-1].ID
+	}
+	if current == "" {
+		current = time.Now().UTC().Format("2006-01-02")
+	}
+
+	found := false
+	for _, key := range keys {
+		found = found || key.ID == current
+	}
+	if !found {
+		key, err := generateKey(dir, current, alg)
+		if err != nil {
+			return nil, err
+		}
+		keys = append(keys, key)
+	}
+
+	return NewKeySet(current, keys)
+}
+
+// readPrivateKey parses a PKCS#8 (RSA or Ed25519) or PKCS#1 (RSA) private key, the kid is the file name
+func readPrivateKey(path string) (Key, error) {
+	kid := strings.TrimSuffix(filepath.Base(path), pemExtension)
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return Key{}, err
+	}
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return Key{}, fmt.Errorf("%s: no PEM block found", path)
+	}
+
+	var private interface{}
+	switch block.Type {
+	case "PRIVATE KEY":
+		private, err = x509.ParsePKCS8PrivateKey(block.Bytes)
+	case "RSA PRIVATE KEY":
+		private, err = x509.ParsePKCS1PrivateKey(block.Bytes)
+	default:
+		err = fmt.Errorf("unsupported PEM block %q", block.Type)
+	}
+	if err != nil {
+		return Key{}, fmt.Errorf("%s: %w", path, err)
+	}
+
+	key, err := newKey(kid, private)
+	if err != nil {
+		return Key{}, fmt.Errorf("%s: %w", path, err)
+	}
+	return key, nil
+}
+
+// newKey wraps a private key with the signing method matching its type
+func newKey(kid string, private interface{}) (Key, error) {
+	switch k := private.(type) {
+	case *rsa.PrivateKey:
+		if k.N.BitLen() < rsaKeyBits {
+			return Key{}, fmt.Errorf("RSA key must be at least %d bits", rsaKeyBits)
+		}
+		return Key{ID: kid, Method: jwt.SigningMethodRS256, signKey: k, verifyKey: &k.PublicKey}, nil
+	case ed25519.PrivateKey:
+		return Key{ID: kid, Method: jwt.SigningMethodEdDSA, signKey: k, verifyKey: k.Public()}, nil
+	default:
+		return Key{}, errors.New("only RSA and Ed25519 keys are supported")
+	}
+}
+
+// generateKey creates a new key pair and stores its private half as <dir>/<kid>.pem
+func generateKey(dir, kid, alg string) (Key, error) {
+	if alg == "" {
+		alg = defaultKeyAlg
+	}
+
+	var private interface{}
+	var err error
+	switch alg {
+	case "RS256":
+		private, err = rsa.GenerateKey(rand.Reader, rsaKeyBits)
+	case "EdDSA":
+		_, private, err = ed25519.GenerateKey(rand.Reader)
+	default:
+		return Key{}, fmt.Errorf("unsupported JWT_ALG %q, use RS256 or EdDSA", alg)
+	}
+	if err != nil {
+		return Key{}, err
+	}
+
+	der, err := x509.MarshalPKCS8PrivateKey(private)
+	if err != nil {
+		return Key{}, err
+	}
+	data := pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: der})
+	path := filepath.Join(dir, kid+pemExtension)
+	if err := os.WriteFile(path, data, 0o600); err != nil {
+		return Key{}, fmt.Errorf("writing %s: %w", path, err)
+	}
+
+	return newKey(kid, private)
+}
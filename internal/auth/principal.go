@@ -0,0 +1,80 @@
+package auth
+
+import (
+	"context"
+	"time"
+
+	"w4/lc3/internal/apperror"
+
+	"github.com/golang-jwt/jwt/v4"
+	"github.com/labstack/echo/v4"
+)
+
+// User roles
+const (
+	RoleCustomer = "customer"
+	RoleAdmin    = "admin"
+)
+
+// Claims is the payload of an access token
+type Claims struct {
+	UserID int    `json:"user_id"`
+	Role   string `json:"role,omitempty"`
+	jwt.RegisteredClaims
+}
+
+// Principal is the authenticated caller of a request, built once from a verified access token
+type Principal struct {
+	UserID    int
+	Roles     []string
+	TokenID   string // jti of the access token, used to revoke it
+	ExpiresAt time.Time
+}
+
+// NewPrincipal builds the principal of verified claims.
+// Tokens issued before roles existed carry no role claim and are treated as customers.
+func NewPrincipal(claims *Claims) *Principal {
+	role := claims.Role
+	if role == "" {
+		role = RoleCustomer
+	}
+	p := &Principal{UserID: claims.UserID, Roles: []string{role}, TokenID: claims.ID}
+	if claims.ExpiresAt != nil {
+		p.ExpiresAt = claims.ExpiresAt.Time
+	}
+	return p
+}
+
+// HasRole reports whether the principal holds one of roles
+func (p *Principal) HasRole(roles ...string) bool {
+	for _, have := range p.Roles {
+		for _, want := range roles {
+			if have == want {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+type principalKey struct{}
+
+// WithPrincipal returns a copy of ctx carrying p
+func WithPrincipal(ctx context.Context, p *Principal) context.Context {
+	return context.WithValue(ctx, principalKey{}, p)
+}
+
+// PrincipalFrom returns the principal carried by ctx, for code below the handlers
+func PrincipalFrom(ctx context.Context) (*Principal, bool) {
+	p, ok := ctx.Value(principalKey{}).(*Principal)
+	return p, ok
+}
+
+// CurrentPrincipal returns the caller of a request that went through the JWT middleware
+func CurrentPrincipal(c echo.Context) (*Principal, error) {
+	p, ok := PrincipalFrom(c.Request().Context())
+	if !ok {
+		return nil, apperror.Unauthorized("Unauthorized")
+	}
+	return p, nil
+}
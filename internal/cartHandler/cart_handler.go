@@ -1,99 +1,184 @@
 package handler
 
 import (
-	"time"
 	"net/http"
-	config "w4/lc3/config/database"
-	utils "w4/lc3/utils"
+	"strconv"
+	"w4/lc3/internal/apperror"
+	"w4/lc3/internal/auth"
+	"w4/lc3/internal/repository"
+	"w4/lc3/internal/service"
 
-	"context"
 	"github.com/labstack/echo/v4"
 )
 
 // Cart struct
-type Cart struct {
-	CartID    int       `json:"cart_id"`
-	UserID    int       `json:"user_id"`
-	ProductID int       `json:"product_id"`
-	Quantity  int       `json:"quantity"`
-	CreatedAt time.Time `json:"created_at"`
+type Cart = repository.CartItem
+
+// CartLine is a cart item enriched with the current product details
+type CartLine = repository.CartLine
+
+// CartView is the response of GET /users/carts
+type CartView = service.CartView
+
+// Handler serves the cart endpoints
+type Handler struct {
+	carts *service.CartService
+}
+
+// New returns the cart handlers backed by carts
+func New(carts *service.CartService) *Handler {
+	return &Handler{carts: carts}
 }
 
 // AddToCartRequest struct
 type AddToCartRequest struct {
-	ProductID int `json:"product_id" validate:"required"`
+	ProductID int `json:"product_id" validate:"required,min=1"`
 	Quantity  int `json:"quantity" validate:"required,min=1"`
 }
 
+// UpdateCartItemRequest struct, a quantity of 0 removes the item
+type UpdateCartItemRequest struct {
+	Quantity *int `json:"quantity" validate:"required,min=0"`
+}
+
 // @Summary Retrieve cart items for the logged-in user
-// @Description Get all cart items belonging to the authenticated user
+// @Description Get all cart items belonging to the authenticated user with product details, line subtotals, availability and the cart totals
 // @Tags Carts
 // @Accept  json
 // @Produce  json
-// @Success 200 {object} map[string]interface{} "List of cart items"
-// @Failure 401 {object} map[string]string "Unauthorized"
-// @Failure 500 {object} map[string]string "Failed to retrieve cart data"
+// @Success 200 {object} CartView "Cart items and summary"
+// @Failure 401 {object} apperror.Error "Unauthorized"
+// @Failure 500 {object} apperror.Error "Failed to retrieve cart data"
 // @Router /users/carts [get]
-func GetCart(c echo.Context) error {
-	// Extract user ID from JWT
-	userID, err := utils.GetUserIDFromToken(c)
+func (h *Handler) GetCart(c echo.Context) error {
+	// Extract user ID from the authenticated principal
+	principal, err := auth.CurrentPrincipal(c)
 	if err != nil {
-		return c.JSON(http.StatusUnauthorized, map[string]string{"message": "Unauthorized"})
+		return err
 	}
+	userID := principal.UserID
 
-	// Query to get cart data
-	query := "SELECT cart_id, user_id, product_id, quantity, created_at FROM carts WHERE user_id = $1"
-	rows, err := config.Pool.Query(context.Background(), query, userID)
+	// Lines come with the current product details, totals with the same calculator checkout uses
+	view, err := h.carts.View(c.Request().Context(), userID)
 	if err != nil {
-		return c.JSON(http.StatusInternalServerError, map[string]string{"message": "Failed to retrieve cart data"})
-	}
-	defer rows.Close()
-
-	// Fetch cart data
-	var cartItems []Cart
-	for rows.Next() {
-		var cart Cart
-		if err := rows.Scan(&cart.CartID, &cart.UserID, &cart.ProductID, &cart.Quantity, &cart.CreatedAt); err != nil {
-			return c.JSON(http.StatusInternalServerError, map[string]string{"message": "Error scanning cart data"})
-		}
-		cartItems = append(cartItems, cart)
+		return err
 	}
 
-	return c.JSON(http.StatusOK, map[string]interface{}{"cart": cartItems})
+	return c.JSON(http.StatusOK, view)
 }
 
 // @Summary Add an item to the user's cart
-// @Description Add a product to the cart for the authenticated user
+// @Description Add a product to the cart for the authenticated user. Adding a product that is already in the cart increases its quantity.
 // @Tags Carts
 // @Accept  json
 // @Produce  json
 // @Param request body AddToCartRequest true "Request body for adding a product to the cart"
-// @Success 201 {object} map[string]string "Item added to cart"
-// @Failure 400 {object} map[string]string "Invalid request"
-// @Failure 401 {object} map[string]string "Unauthorized"
-// @Failure 500 {object} map[string]string "Failed to add to cart"
+// @Success 201 {object} map[string]interface{} "Item added to cart"
+// @Failure 400 {object} apperror.Error "Invalid request"
+// @Failure 401 {object} apperror.Error "Unauthorized"
+// @Failure 404 {object} apperror.Error "Product not found"
+// @Failure 409 {object} apperror.Error "Requested quantity exceeds available stock"
+// @Failure 500 {object} apperror.Error "Failed to add to cart"
 // @Router /users/carts [post]
-func AddToCart(c echo.Context) error {
-	// Extract user ID from JWT
-	userID, err := utils.GetUserIDFromToken(c)
+func (h *Handler) AddToCart(c echo.Context) error {
+	// Extract user ID from the authenticated principal
+	principal, err := auth.CurrentPrincipal(c)
 	if err != nil {
-		return c.JSON(http.StatusUnauthorized, map[string]string{"message": "Unauthorized"})
+		return err
 	}
+	userID := principal.UserID
 
 	// Parse request body
 	var req AddToCartRequest
 	if err := c.Bind(&req); err != nil {
-		return c.JSON(http.StatusBadRequest, map[string]string{"message": "Invalid request"})
+		return apperror.BadRequest("Invalid request")
+	}
+	if err := c.Validate(&req); err != nil {
+		return err
+	}
+
+	// Adding a product already in the cart adds to its quantity, within the available stock
+	cart, err := h.carts.Add(c.Request().Context(), userID, req.ProductID, req.Quantity)
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(http.StatusCreated, map[string]interface{}{"message": "Item added to cart", "cart": cart})
+}
+
+// @Summary Update the quantity of a cart item
+// @Description Set an absolute quantity for a cart item of the authenticated user. A quantity of 0 removes the item.
+// @Tags Carts
+// @Accept  json
+// @Produce  json
+// @Param id path int true "Cart ID"
+// @Param request body UpdateCartItemRequest true "New quantity"
+// @Success 200 {object} map[string]interface{} "Cart item updated"
+// @Failure 400 {object} apperror.Error "Invalid request"
+// @Failure 401 {object} apperror.Error "Unauthorized"
+// @Failure 404 {object} apperror.Error "Cart item not found"
+// @Failure 409 {object} apperror.Error "Requested quantity exceeds available stock"
+// @Failure 500 {object} apperror.Error "Failed to update item"
+// @Router /users/carts/{id} [patch]
+func (h *Handler) UpdateCartItem(c echo.Context) error {
+	// Extract user ID from the authenticated principal
+	principal, err := auth.CurrentPrincipal(c)
+	if err != nil {
+		return err
+	}
+	userID := principal.UserID
+
+	// Get cart ID from URL params
+	cartID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		return apperror.BadRequest("Invalid cart ID")
+	}
+
+	// Parse request body
+	var req UpdateCartItemRequest
+	if err := c.Bind(&req); err != nil {
+		return apperror.BadRequest("Invalid request")
+	}
+	if err := c.Validate(&req); err != nil {
+		return err
+	}
+
+	// A quantity of 0 removes the line
+	if *req.Quantity == 0 {
+		return h.deleteCartItem(c, cartID, userID)
+	}
+
+	cart, err := h.carts.SetQuantity(c.Request().Context(), userID, cartID, *req.Quantity)
+	if err != nil {
+		return err
 	}
 
-	// Insert into cart
-	query := "INSERT INTO carts (user_id, product_id, quantity) VALUES ($1, $2, $3)"
-	_, err = config.Pool.Exec(context.Background(), query, userID, req.ProductID, req.Quantity)
+	return c.JSON(http.StatusOK, map[string]interface{}{"message": "Cart item updated", "cart": cart})
+}
+
+// @Summary Empty the user's cart
+// @Description Remove every item from the cart of the authenticated user
+// @Tags Carts
+// @Accept  json
+// @Produce  json
+// @Success 200 {object} map[string]interface{} "Cart emptied"
+// @Failure 401 {object} apperror.Error "Unauthorized"
+// @Failure 500 {object} apperror.Error "Failed to empty cart"
+// @Router /users/carts [delete]
+func (h *Handler) ClearCart(c echo.Context) error {
+	// Extract user ID from the authenticated principal
+	principal, err := auth.CurrentPrincipal(c)
+	if err != nil {
+		return err
+	}
+	userID := principal.UserID
+
+	removed, err := h.carts.Clear(c.Request().Context(), userID)
 	if err != nil {
-		return c.JSON(http.StatusInternalServerError, map[string]string{"message": "Failed to add to cart"})
+		return err
 	}
 
-	return c.JSON(http.StatusCreated, map[string]string{"message": "Item added to cart"})
+	return c.JSON(http.StatusOK, map[string]interface{}{"message": "Cart emptied", "removed": removed})
 }
 
 // @Summary Delete a specific item from the user's cart
@@ -103,31 +188,33 @@ func AddToCart(c echo.Context) error {
 // @Produce  json
 // @Param id path int true "Cart ID"
 // @Success 200 {object} map[string]string "Item deleted from cart"
-// @Failure 401 {object} map[string]string "Unauthorized"
-// @Failure 404 {object} map[string]string "Cart item not found"
-// @Failure 500 {object} map[string]string "Failed to delete item"
+// @Failure 400 {object} apperror.Error "Invalid cart ID"
+// @Failure 401 {object} apperror.Error "Unauthorized"
+// @Failure 404 {object} apperror.Error "Cart item not found"
+// @Failure 500 {object} apperror.Error "Failed to delete item"
 // @Router /users/carts/{id} [delete]
-func DeleteCartItem(c echo.Context) error {
-	// Extract user ID from JWT
-	userID, err := utils.GetUserIDFromToken(c)
+func (h *Handler) DeleteCartItem(c echo.Context) error {
+	// Extract user ID from the authenticated principal
+	principal, err := auth.CurrentPrincipal(c)
 	if err != nil {
-		return c.JSON(http.StatusUnauthorized, map[string]string{"message": "Unauthorized"})
+		return err
 	}
+	userID := principal.UserID
 
 	// Get cart ID from URL params
-	cartID := c.Param("id")
-
-	// Delete item where user_id and cart_id match
-	query := "DELETE FROM carts WHERE cart_id = $1 AND user_id = $2"
-	result, err := config.Pool.Exec(context.Background(), query, cartID, userID)
+	cartID, err := strconv.Atoi(c.Param("id"))
 	if err != nil {
-		return c.JSON(http.StatusInternalServerError, map[string]string{"message": "Failed to delete item"})
+		return apperror.BadRequest("Invalid cart ID")
 	}
 
-	// Check if any row was affected
-	if result.RowsAffected() == 0 {
-		return c.JSON(http.StatusNotFound, map[string]string{"message": "Cart item not found"})
+	return h.deleteCartItem(c, cartID, userID)
+}
+
+// deleteCartItem removes one cart line owned by the user
+func (h *Handler) deleteCartItem(c echo.Context, cartID, userID int) error {
+	if err := h.carts.Remove(c.Request().Context(), userID, cartID); err != nil {
+		return err
 	}
 
 	return c.JSON(http.StatusOK, map[string]string{"message": "Item deleted from cart"})
-}
\ No newline at end of file
+}
@@ -0,0 +1,82 @@
+package handler
+
+import (
+	"net/http"
+	"strconv"
+
+	"w4/lc3/internal/apperror"
+	product_handler "w4/lc3/internal/productHandler"
+	"w4/lc3/internal/repository"
+	"w4/lc3/internal/service"
+
+	"github.com/labstack/echo/v4"
+)
+
+// Category struct, Children is filled when the categories are returned as a tree
+type Category = repository.Category
+
+// Handler serves the category endpoints
+type Handler struct {
+	categories *service.CategoryService
+}
+
+// New returns the category handlers backed by categories
+func New(categories *service.CategoryService) *Handler {
+	return &Handler{categories: categories}
+}
+
+// @Summary Get Category Tree
+// @Description Retrieve all categories nested under their parents.
+// @Tags Categories
+// @Accept  json
+// @Produce  json
+// @Success 200 {object} map[string]interface{} "Category tree"
+// @Failure 500 {object} apperror.Error "Internal Server Error"
+// @Router /categories [get]
+func (h *Handler) GetCategories(c echo.Context) error {
+	tree, err := h.categories.Tree(c.Request().Context())
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(http.StatusOK, map[string]interface{}{"categories": tree})
+}
+
+// @Summary Get Products by Category
+// @Description Retrieve a page of products in a category or any of its subcategories. Supports the same options as GET /products.
+// @Tags Categories
+// @Accept  json
+// @Produce  json
+// @Param id path int true "Category ID"
+// @Param page query int false "Page number, starting at 1, up to 10000 rows deep"
+// @Param limit query int false "Page size, 1-100 (default 20)"
+// @Param after query string false "Cursor from pagination.next_cursor, replaces page and needs the same sort"
+// @Param min_price query number false "Minimum price"
+// @Param max_price query number false "Maximum price"
+// @Param name query string false "Case-insensitive name search"
+// @Param sort query string false "Sort order" Enums(price, -price, name, newest)
+// @Success 200 {object} product_handler.ProductPage "List of products"
+// @Failure 400 {object} apperror.Error "Invalid query parameters"
+// @Failure 404 {object} apperror.Error "Category not found"
+// @Failure 500 {object} apperror.Error "Internal Server Error"
+// @Router /categories/{id}/products [get]
+func (h *Handler) GetCategoryProducts(c echo.Context) error {
+	// Extract category ID from URL params
+	categoryID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		return apperror.BadRequest("Invalid category ID")
+	}
+
+	filter, err := product_handler.ParseProductFilter(c)
+	if err != nil {
+		return apperror.BadRequest(err.Error())
+	}
+
+	// An unknown category is a 404 rather than an empty page
+	page, err := h.categories.Products(c.Request().Context(), categoryID, filter)
+	if err != nil {
+		return err
+	}
+
+	return product_handler.RespondProductPage(c, filter, page)
+}
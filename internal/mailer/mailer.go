@@ -0,0 +1,66 @@
+package mailer
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strconv"
+)
+
+// Message is a plain-text email
+type Message struct {
+	To      string
+	Subject string
+	Body    string
+}
+
+// Mailer sends emails. Handlers depend on this interface so local runs and tests
+// can swap the SMTP server for a mailer that only writes the messages down.
+type Mailer interface {
+	Send(ctx context.Context, msg Message) error
+}
+
+// Default is the mailer used by the API, set by Init
+var Default Mailer = NewLogMailer("")
+
+// Init configures Default from the environment.
+// MAIL_DRIVER=smtp sends through SMTP_HOST/SMTP_PORT/SMTP_USERNAME/SMTP_PASSWORD, MAIL_DRIVER=log
+// writes each message to MAIL_DIR, or to the log when MAIL_DIR is empty. The driver must be set
+// unless APP_ENV=development, a deployment must not quietly stop sending its emails.
+func Init() error {
+	from := os.Getenv("MAIL_FROM")
+	if from == "" {
+		from = "no-reply@localhost"
+	}
+
+	switch driver := os.Getenv("MAIL_DRIVER"); driver {
+	case "", "log":
+		if driver == "" && os.Getenv("APP_ENV") != "development" {
+			return fmt.Errorf("MAIL_DRIVER is required, use smtp, or log for local runs")
+		}
+		Default = NewLogMailer(os.Getenv("MAIL_DIR"))
+	case "smtp":
+		port := 587
+		if v := os.Getenv("SMTP_PORT"); v != "" {
+			p, err := strconv.Atoi(v)
+			if err != nil {
+				return fmt.Errorf("SMTP_PORT must be a number: %w", err)
+			}
+			port = p
+		}
+		host := os.Getenv("SMTP_HOST")
+		if host == "" {
+			return fmt.Errorf("SMTP_HOST is required when MAIL_DRIVER=smtp")
+		}
+		Default = &SMTPMailer{
+			Host:     host,
+			Port:     port,
+			Username: os.Getenv("SMTP_USERNAME"),
+			Password: os.Getenv("SMTP_PASSWORD"),
+			From:     from,
+		}
+	default:
+		return fmt.Errorf("unknown MAIL_DRIVER %q, use smtp or log", driver)
+	}
+	return nil
+}
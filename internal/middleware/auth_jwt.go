@@ -1,40 +1,62 @@
 package middleware
 
 import (
+	"context"
 	"net/http"
 	"strings"
+	"time"
+	"w4/lc3/internal/apperror"
+	"w4/lc3/internal/auth"
 
-	"github.com/golang-jwt/jwt/v4"
 	"github.com/labstack/echo/v4"
 )
 
-// Define the JWT secret key
-var jwtSecret = []byte("12345")
+// RevocationChecker tells whether an access token was revoked by a logout
+type RevocationChecker interface {
+	IsRevoked(ctx context.Context, jti string) (bool, error)
+}
 
-func JWTMiddleware(next echo.HandlerFunc) echo.HandlerFunc {
-	return func(c echo.Context) error {
-		authHeader := c.Request().Header.Get("Authorization")
-		if authHeader == "" {
-			return c.JSON(http.StatusUnauthorized, map[string]string{"message": "Missing token"})
-		}
+// NewJWTMiddleware returns the middleware accepting access tokens signed by keys and not revoked according to checker
+func NewJWTMiddleware(keys *auth.KeySet, checker RevocationChecker) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			authHeader := c.Request().Header.Get("Authorization")
+			if authHeader == "" {
+				return apperror.Unauthorized("Missing token")
+			}
 
-		// Extract token from "Bearer <token>"
-		parts := strings.Split(authHeader, " ")
-		if len(parts) != 2 || parts[0] != "Bearer" {
-			return c.JSON(http.StatusUnauthorized, map[string]string{"message": "Invalid token format"})
-		}
-		tokenString := parts[1]
-
-		// Parse the token
-		token, err := jwt.Parse(tokenString, func(token *jwt.Token) (interface{}, error) {
-			return jwtSecret, nil
-		})
-		if err != nil || !token.Valid {
-			return c.JSON(http.StatusUnauthorized, map[string]string{"message": "Invalid token"})
-		}
+			// Extract token from "Bearer <token>", the scheme is case-insensitive
+			parts := strings.Split(authHeader, " ")
+			if len(parts) != 2 || !strings.EqualFold(parts[0], "Bearer") {
+				return apperror.Unauthorized("Invalid token format")
+			}
+			tokenString := parts[1]
 
-		// Attach token to context
-		c.Set("user", token)
-		return next(c)
+			// Parse the token, checking its algorithm and kid against the configured keys
+			claims := &auth.Claims{}
+			token, err := keys.Parse(tokenString, claims)
+			if err != nil || !token.Valid || claims.UserID == 0 {
+				return apperror.Unauthorized("Invalid token")
+			}
+
+			// Reject tokens revoked by a logout, tokens without a jti can't be revoked so they aren't accepted
+			if claims.ID == "" {
+				return apperror.Unauthorized("Invalid token")
+			}
+			ctx, cancel := context.WithTimeout(c.Request().Context(), 5*time.Second)
+			revoked, err := checker.IsRevoked(ctx, claims.ID)
+			cancel()
+			if err != nil {
+				return apperror.Internal("Failed to check token", err)
+			}
+			if revoked {
+				return apperror.New(http.StatusUnauthorized, apperror.CodeTokenRevoked, "Token has been revoked")
+			}
+
+			// Attach the principal to the request context, handlers read it with auth.CurrentPrincipal
+			ctx = auth.WithPrincipal(c.Request().Context(), auth.NewPrincipal(claims))
+			c.SetRequest(c.Request().WithContext(ctx))
+			return next(c)
+		}
 	}
 }
@@ -0,0 +1,33 @@
+package middleware
+
+import (
+	"w4/lc3/internal/apperror"
+	"w4/lc3/internal/auth"
+
+	"github.com/labstack/echo/v4"
+)
+
+// User roles
+const (
+	RoleCustomer = auth.RoleCustomer
+	RoleAdmin    = auth.RoleAdmin
+)
+
+// RequireRole only lets the request through when the principal holds one of roles.
+// It must be chained after JWTMiddleware, e.g. e.POST("products", h, JWTMiddleware, RequireRole(RoleAdmin)).
+func RequireRole(roles ...string) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			principal, err := auth.CurrentPrincipal(c)
+			if err != nil {
+				return err
+			}
+
+			if principal.HasRole(roles...) {
+				return next(c)
+			}
+
+			return apperror.Forbidden("you're not authorized to perform this action")
+		}
+	}
+}
@@ -1,30 +1,37 @@
 package handler
 
 import (
-	"time"
 	"github.com/labstack/echo/v4"
-	config "w4/lc3/config/database"
-	utils "w4/lc3/utils"
 	"net/http"
-	"context"
+	"strconv"
+	"w4/lc3/internal/apperror"
+	"w4/lc3/internal/auth"
+	"w4/lc3/internal/pricing"
+	"w4/lc3/internal/repository"
+	"w4/lc3/internal/service"
 )
 
-type Order struct {
-	OrderID    int       `json:"order_id"`
-	UserID     int       `json:"user_id"`
-	TotalPrice float64   `json:"total_price"`
-	CreatedAt  time.Time `json:"created_at"`
+type Order = repository.Order
+
+type OrderItem = repository.OrderItem
+
+type OrderDetail = service.OrderDetail
+
+// Handler serves the order endpoints
+type Handler struct {
+	orders *service.OrderService
 }
 
-type CartItem struct {
-	ProductID int `json:"product_id"`
-	Quantity  int `json:"quantity"`
+// New returns the order handlers backed by orders
+func New(orders *service.OrderService) *Handler {
+	return &Handler{orders: orders}
 }
 
 type AddOrderResponse struct {
-	Message   string  `json:"message"`
-	OrderID   int     `json:"order_id"`
-	TotalPrice float64 `json:"total_price"`
+	Message    string          `json:"message"`
+	OrderID    int             `json:"order_id"`
+	TotalPrice float64         `json:"total_price"`
+	Summary    pricing.Summary `json:"summary"`
 }
 
 // @Summary Get User Orders
@@ -33,102 +40,91 @@ type AddOrderResponse struct {
 // @Accept  json
 // @Produce  json
 // @Success 200 {object} map[string]interface{} "List of user orders"
-// @Failure 401 {object} map[string]string "Unauthorized"
-// @Failure 500 {object} map[string]string "Internal Server Error"
+// @Failure 401 {object} apperror.Error "Unauthorized"
+// @Failure 500 {object} apperror.Error "Internal Server Error"
 // @Router /users/orders [get]
-func GetOrders(c echo.Context) error {
-	// Extract user ID from JWT
-	userID, err := utils.GetUserIDFromToken(c)
+func (h *Handler) GetOrders(c echo.Context) error {
+	// Extract user ID from the authenticated principal
+	principal, err := auth.CurrentPrincipal(c)
 	if err != nil {
-		return c.JSON(http.StatusUnauthorized, map[string]string{"message": "Unauthorized"})
+		return err
 	}
+	userID := principal.UserID
 
-	// Query to fetch user orders
-	query := "SELECT order_id, user_id, total_price, created_at FROM orders WHERE user_id = $1"
-	rows, err := config.Pool.Query(context.Background(), query, userID)
+	orders, err := h.orders.List(c.Request().Context(), userID)
 	if err != nil {
-		return c.JSON(http.StatusInternalServerError, map[string]string{"message": "Failed to retrieve orders"})
-	}
-	defer rows.Close()
-
-	// Parse the result
-	var orders []Order
-	for rows.Next() {
-		var order Order
-		if err := rows.Scan(&order.OrderID, &order.UserID, &order.TotalPrice, &order.CreatedAt); err != nil {
-			return c.JSON(http.StatusInternalServerError, map[string]string{"message": "Error scanning orders"})
-		}
-		orders = append(orders, order)
+		return err
 	}
 
 	return c.JSON(http.StatusOK, map[string]interface{}{"orders": orders})
 }
 
-// @Summary Add a New Order
-// @Description Place a new order for the logged-in user. Cart items are processed, and the cart is cleared after order creation.
+// @Summary Get Order Detail
+// @Description Retrieve a single order of the logged-in user together with its line items.
 // @Tags Orders
 // @Accept  json
 // @Produce  json
-// @Success 201 {object} AddOrderResponse "Order placed successfully"
-// @Failure 400 {object} map[string]string "Bad Request - Cart is empty"
-// @Failure 401 {object} map[string]string "Unauthorized"
-// @Failure 500 {object} map[string]string "Internal Server Error"
-// @Router /users/orders [post]
-func AddOrder(c echo.Context) error {
-	// Extract user ID from JWT
-	userID, err := utils.GetUserIDFromToken(c)
+// @Param id path int true "Order ID"
+// @Success 200 {object} OrderDetail "Order with line items"
+// @Failure 400 {object} apperror.Error "Invalid order ID"
+// @Failure 401 {object} apperror.Error "Unauthorized"
+// @Failure 404 {object} apperror.Error "Order not found"
+// @Failure 500 {object} apperror.Error "Internal Server Error"
+// @Router /users/orders/{id} [get]
+func (h *Handler) GetOrderByID(c echo.Context) error {
+	// Extract user ID from the authenticated principal
+	principal, err := auth.CurrentPrincipal(c)
 	if err != nil {
-		return c.JSON(http.StatusUnauthorized, map[string]string{"message": "Unauthorized"})
+		return err
 	}
+	userID := principal.UserID
 
-	// Step 1: Fetch all cart items for the user
-	queryCart := "SELECT product_id, quantity FROM carts WHERE user_id = $1"
-	rows, err := config.Pool.Query(context.Background(), queryCart, userID)
+	// Get order ID from URL params
+	orderID, err := strconv.Atoi(c.Param("id"))
 	if err != nil {
-		return c.JSON(http.StatusInternalServerError, map[string]string{"message": "Failed to fetch cart items"})
-	}
-	defer rows.Close()
-
-	var cartItems []CartItem
-	for rows.Next() {
-		var item CartItem
-		if err := rows.Scan(&item.ProductID, &item.Quantity); err != nil {
-			return c.JSON(http.StatusInternalServerError, map[string]string{"message": "Error parsing cart data"})
-		}
-		cartItems = append(cartItems, item)
+		return apperror.BadRequest("Invalid order ID")
 	}
 
-	// Check if cart is empty
-	if len(cartItems) == 0 {
-		return c.JSON(http.StatusBadRequest, map[string]string{"message": "Cart is empty"})
+	// Orders of other users look missing
+	order, err := h.orders.Detail(c.Request().Context(), userID, orderID)
+	if err != nil {
+		return err
 	}
 
-	// Step 2: Calculate total price (mock product price as $10 for demonstration)
-	var totalPrice float64
-	for _, item := range cartItems {
-		productPrice := 10.0 // Replace this with a query to fetch actual product prices
-		totalPrice += float64(item.Quantity) * productPrice
-	}
+	return c.JSON(http.StatusOK, order)
+}
 
-	// Step 3: Insert new order into the orders table
-	queryOrder := "INSERT INTO orders (user_id, total_price) VALUES ($1, $2) RETURNING order_id"
-	var orderID int
-	err = config.Pool.QueryRow(context.Background(), queryOrder, userID, totalPrice).Scan(&orderID)
+// @Summary Add a New Order
+// @Description Place a new order for the logged-in user. Cart items are priced from the catalog, saved as order items, and the cart is cleared in the same transaction.
+// @Tags Orders
+// @Accept  json
+// @Produce  json
+// @Success 201 {object} AddOrderResponse "Order placed successfully"
+// @Failure 400 {object} apperror.Error "Bad Request - Cart is empty"
+// @Failure 401 {object} apperror.Error "Unauthorized"
+// @Failure 403 {object} apperror.Error "Email not verified"
+// @Failure 409 {object} apperror.Error "Insufficient stock"
+// @Failure 500 {object} apperror.Error "Internal Server Error"
+// @Router /users/orders [post]
+func (h *Handler) AddOrder(c echo.Context) error {
+	// Extract user ID from the authenticated principal
+	principal, err := auth.CurrentPrincipal(c)
 	if err != nil {
-		return c.JSON(http.StatusInternalServerError, map[string]string{"message": "Failed to create order"})
+		return err
 	}
+	userID := principal.UserID
 
-	// Step 4: Clear the user's cart
-	queryDeleteCart := "DELETE FROM carts WHERE user_id = $1"
-	_, err = config.Pool.Exec(context.Background(), queryDeleteCart, userID)
+	// Only verified accounts can check out, the whole checkout runs in one transaction
+	receipt, err := h.orders.Checkout(c.Request().Context(), userID)
 	if err != nil {
-		return c.JSON(http.StatusInternalServerError, map[string]string{"message": "Failed to clear cart"})
+		return err
 	}
 
-	// Step 5: Return success response
+	// Return success response
 	return c.JSON(http.StatusCreated, AddOrderResponse{
 		Message:    "Order placed successfully",
-		OrderID:    orderID,
-		TotalPrice: totalPrice,
+		OrderID:    receipt.OrderID,
+		TotalPrice: receipt.Summary.GrandTotal,
+		Summary:    receipt.Summary,
 	})
-}
\ No newline at end of file
+}
@@ -0,0 +1,114 @@
+package handler
+
+import (
+	"net/http"
+	"strconv"
+
+	"w4/lc3/internal/apperror"
+	"w4/lc3/internal/auth"
+	"w4/lc3/internal/repository"
+
+	"github.com/labstack/echo/v4"
+)
+
+// StatusChange is one entry of an order's status history
+type StatusChange = repository.StatusChange
+
+// UpdateOrderStatusRequest struct
+type UpdateOrderStatusRequest struct {
+	Status string `json:"status" validate:"required,oneof=pending paid shipped delivered cancelled"`
+}
+
+// UpdateOrderStatusResponse struct
+type UpdateOrderStatusResponse struct {
+	Message string `json:"message"`
+	OrderID int    `json:"order_id"`
+	Status  string `json:"status"`
+}
+
+// statusChangeResponse is the success response of a status change
+func statusChangeResponse(c echo.Context, orderID int, status string) error {
+	return c.JSON(http.StatusOK, UpdateOrderStatusResponse{
+		Message: "Order status updated",
+		OrderID: orderID,
+		Status:  status,
+	})
+}
+
+// @Summary Cancel an Order
+// @Description Cancel an order of the logged-in user. Only pending orders can be cancelled.
+// @Tags Orders
+// @Accept  json
+// @Produce  json
+// @Param id path int true "Order ID"
+// @Success 200 {object} UpdateOrderStatusResponse "Order cancelled"
+// @Failure 400 {object} apperror.Error "Invalid order ID"
+// @Failure 401 {object} apperror.Error "Unauthorized"
+// @Failure 404 {object} apperror.Error "Order not found"
+// @Failure 409 {object} apperror.Error "Order can no longer be cancelled"
+// @Failure 500 {object} apperror.Error "Internal Server Error"
+// @Router /users/orders/{id}/cancel [post]
+func (h *Handler) CancelOrder(c echo.Context) error {
+	// Extract user ID from the authenticated principal
+	principal, err := auth.CurrentPrincipal(c)
+	if err != nil {
+		return err
+	}
+	userID := principal.UserID
+
+	// Get order ID from URL params
+	orderID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		return apperror.BadRequest("Invalid order ID")
+	}
+
+	// Only pending orders of the caller can be cancelled
+	if err := h.orders.Cancel(c.Request().Context(), userID, orderID); err != nil {
+		return err
+	}
+	return statusChangeResponse(c, orderID, repository.StatusCancelled)
+}
+
+// @Summary Update Order Status
+// @Description Move an order to its next status. Admin only.
+// @Tags Orders
+// @Accept  json
+// @Produce  json
+// @Param id path int true "Order ID"
+// @Param request body UpdateOrderStatusRequest true "New status"
+// @Success 200 {object} UpdateOrderStatusResponse "Order status updated"
+// @Failure 400 {object} apperror.Error "Invalid request"
+// @Failure 401 {object} apperror.Error "Unauthorized"
+// @Failure 403 {object} apperror.Error "Forbidden"
+// @Failure 404 {object} apperror.Error "Order not found"
+// @Failure 409 {object} apperror.Error "Invalid status transition"
+// @Failure 500 {object} apperror.Error "Internal Server Error"
+// @Router /admin/orders/{id}/status [patch]
+func (h *Handler) UpdateOrderStatus(c echo.Context) error {
+	// Extract user ID from the authenticated principal
+	principal, err := auth.CurrentPrincipal(c)
+	if err != nil {
+		return err
+	}
+	userID := principal.UserID
+
+	// Get order ID from URL params
+	orderID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		return apperror.BadRequest("Invalid order ID")
+	}
+
+	// Parse request body
+	var req UpdateOrderStatusRequest
+	if err := c.Bind(&req); err != nil {
+		return apperror.BadRequest("Invalid request")
+	}
+	if err := c.Validate(&req); err != nil {
+		return err
+	}
+
+	if err := h.orders.UpdateStatus(c.Request().Context(), userID, orderID, req.Status); err != nil {
+		return err
+	}
+	return statusChangeResponse(c, orderID, req.Status)
+}
@@ -0,0 +1,92 @@
+package pricing
+
+import (
+	"math"
+	"os"
+	"strconv"
+)
+
+// Line is one product line to be priced
+type Line struct {
+	ProductID int
+	UnitPrice float64
+	Quantity  int
+}
+
+// Summary holds the totals of a cart or an order
+type Summary struct {
+	ItemCount  int     `json:"item_count"`
+	Subtotal   float64 `json:"subtotal"`
+	Discount   float64 `json:"discount"`
+	Tax        float64 `json:"tax"`
+	GrandTotal float64 `json:"grand_total"`
+}
+
+// DiscountRule returns the discount in cents for the given lines and subtotal in cents
+type DiscountRule func(lines []Line, subtotalCents int64) int64
+
+// Calculator prices a set of lines. The cart view and checkout both go through it,
+// so the total shown to the user is the total that gets charged.
+type Calculator struct {
+	TaxRate   float64 // e.g. 0.11 for 11%, applied after discounts
+	Discounts []DiscountRule
+}
+
+// Summarize prices the lines with the default calculator
+func Summarize(lines []Line) Summary {
+	return Default().Summarize(lines)
+}
+
+// Default returns the calculator configured from the environment, TAX_RATE defaults to 0
+func Default() Calculator {
+	rate, err := strconv.ParseFloat(os.Getenv("TAX_RATE"), 64)
+	if err != nil || rate < 0 {
+		rate = 0
+	}
+	return Calculator{TaxRate: rate}
+}
+
+// Summarize computes item count, subtotal, discount, tax and grand total.
+// Amounts are added up in cents so rounding happens once per line and once for tax.
+func (calc Calculator) Summarize(lines []Line) Summary {
+	var summary Summary
+	var subtotal int64
+	for _, line := range lines {
+		summary.ItemCount += line.Quantity
+		subtotal += LineTotalCents(line)
+	}
+
+	var discount int64
+	for _, rule := range calc.Discounts {
+		discount += rule(lines, subtotal)
+	}
+	if discount > subtotal {
+		discount = subtotal
+	}
+
+	tax := int64(math.Round(float64(subtotal-discount) * calc.TaxRate))
+
+	summary.Subtotal = fromCents(subtotal)
+	summary.Discount = fromCents(discount)
+	summary.Tax = fromCents(tax)
+	summary.GrandTotal = fromCents(subtotal - discount + tax)
+	return summary
+}
+
+// LineTotalCents returns unit price times quantity in cents
+func LineTotalCents(line Line) int64 {
+	return toCents(line.UnitPrice) * int64(line.Quantity)
+}
+
+// LineTotal returns unit price times quantity
+func LineTotal(line Line) float64 {
+	return fromCents(LineTotalCents(line))
+}
+
+func toCents(amount float64) int64 {
+	return int64(math.Round(amount * 100))
+}
+
+func fromCents(cents int64) float64 {
+	return float64(cents) / 100
+}
@@ -0,0 +1,175 @@
+package handler
+
+import (
+	"net/http"
+	"strconv"
+
+	"w4/lc3/internal/apperror"
+	"w4/lc3/internal/service"
+
+	"github.com/labstack/echo/v4"
+)
+
+// ProductRequest struct, used to create or fully replace a product
+type ProductRequest struct {
+	Name        string  `json:"name" validate:"required,notblank,max=100"`
+	Description string  `json:"description"`
+	Price       float64 `json:"price" validate:"required,gt=0,max=99999999.99"`
+	Stock       int     `json:"stock" validate:"min=0"` // Initial stock, only used on create
+}
+
+// PatchProductRequest struct, only the fields present are updated
+type PatchProductRequest struct {
+	Name        *string  `json:"name" validate:"omitempty,notblank,max=100"`
+	Description *string  `json:"description"`
+	Price       *float64 `json:"price" validate:"omitempty,gt=0,max=99999999.99"`
+}
+
+// @Summary Create Product
+// @Description Add a new product to the catalog. Admin only.
+// @Tags Products
+// @Accept  json
+// @Produce  json
+// @Param request body ProductRequest true "Product data"
+// @Success 201 {object} Product "Product created"
+// @Failure 400 {object} apperror.Error "Invalid request"
+// @Failure 401 {object} apperror.Error "Unauthorized"
+// @Failure 403 {object} apperror.Error "Forbidden"
+// @Failure 500 {object} apperror.Error "Internal Server Error"
+// @Router /products [post]
+func (h *Handler) CreateProduct(c echo.Context) error {
+	// Parse request body
+	var req ProductRequest
+	if err := c.Bind(&req); err != nil {
+		return apperror.BadRequest("Invalid request")
+	}
+	if err := c.Validate(&req); err != nil {
+		return err
+	}
+
+	// Insert the product
+	product, err := h.products.Create(c.Request().Context(), Product{
+		Name:        req.Name,
+		Description: req.Description,
+		Price:       req.Price,
+		Stock:       req.Stock,
+	})
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(http.StatusCreated, product)
+}
+
+// @Summary Replace Product
+// @Description Replace the name, description and price of a product. Admin only.
+// @Tags Products
+// @Accept  json
+// @Produce  json
+// @Param id path int true "Product ID"
+// @Param request body ProductRequest true "Product data"
+// @Success 200 {object} Product "Product updated"
+// @Failure 400 {object} apperror.Error "Invalid request"
+// @Failure 401 {object} apperror.Error "Unauthorized"
+// @Failure 403 {object} apperror.Error "Forbidden"
+// @Failure 404 {object} apperror.Error "Product not found"
+// @Failure 500 {object} apperror.Error "Internal Server Error"
+// @Router /products/{id} [put]
+func (h *Handler) UpdateProduct(c echo.Context) error {
+	// Extract product ID from URL params
+	productID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		return apperror.BadRequest("Invalid product ID")
+	}
+
+	// Parse request body
+	var req ProductRequest
+	if err := c.Bind(&req); err != nil {
+		return apperror.BadRequest("Invalid request")
+	}
+	if err := c.Validate(&req); err != nil {
+		return err
+	}
+
+	product, err := h.products.Replace(c.Request().Context(), Product{
+		ProductID:   productID,
+		Name:        req.Name,
+		Description: req.Description,
+		Price:       req.Price,
+	})
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(http.StatusOK, product)
+}
+
+// @Summary Update Product
+// @Description Update some fields of a product. Admin only.
+// @Tags Products
+// @Accept  json
+// @Produce  json
+// @Param id path int true "Product ID"
+// @Param request body PatchProductRequest true "Fields to update"
+// @Success 200 {object} Product "Product updated"
+// @Failure 400 {object} apperror.Error "Invalid request"
+// @Failure 401 {object} apperror.Error "Unauthorized"
+// @Failure 403 {object} apperror.Error "Forbidden"
+// @Failure 404 {object} apperror.Error "Product not found"
+// @Failure 500 {object} apperror.Error "Internal Server Error"
+// @Router /products/{id} [patch]
+func (h *Handler) PatchProduct(c echo.Context) error {
+	// Extract product ID from URL params
+	productID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		return apperror.BadRequest("Invalid product ID")
+	}
+
+	// Parse request body
+	var req PatchProductRequest
+	if err := c.Bind(&req); err != nil {
+		return apperror.BadRequest("Invalid request")
+	}
+	if err := c.Validate(&req); err != nil {
+		return err
+	}
+
+	// Only the fields that were sent are overlaid on the current product
+	product, err := h.products.Patch(c.Request().Context(), productID, service.ProductPatch{
+		Name:        req.Name,
+		Description: req.Description,
+		Price:       req.Price,
+	})
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(http.StatusOK, product)
+}
+
+// @Summary Delete Product
+// @Description Soft delete a product so it disappears from the catalog while past order items keep referencing it. Admin only.
+// @Tags Products
+// @Accept  json
+// @Produce  json
+// @Param id path int true "Product ID"
+// @Success 200 {object} map[string]string "Product deleted"
+// @Failure 400 {object} apperror.Error "Invalid product ID"
+// @Failure 401 {object} apperror.Error "Unauthorized"
+// @Failure 403 {object} apperror.Error "Forbidden"
+// @Failure 404 {object} apperror.Error "Product not found"
+// @Failure 500 {object} apperror.Error "Internal Server Error"
+// @Router /products/{id} [delete]
+func (h *Handler) DeleteProduct(c echo.Context) error {
+	// Extract product ID from URL params
+	productID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		return apperror.BadRequest("Invalid product ID")
+	}
+
+	if err := h.products.Delete(c.Request().Context(), productID); err != nil {
+		return err
+	}
+
+	return c.JSON(http.StatusOK, map[string]string{"message": "Product deleted"})
+}
@@ -2,48 +2,55 @@ package handler
 
 import (
 	"github.com/labstack/echo/v4"
-	config "w4/lc3/config/database"
-	// utils "w4/lc3/utils"
 	"net/http"
-	"context"
+	"strconv"
+	"w4/lc3/internal/apperror"
+	"w4/lc3/internal/repository"
+	"w4/lc3/internal/service"
 )
 
-type Product struct {
-	ProductID   int     `json:"product_id"`
-	Name        string  `json:"name"`
-	Description string  `json:"description"`
-	Price       float64 `json:"price"`
+type Product = repository.Product
+
+// Handler serves the catalog endpoints
+type Handler struct {
+	products *service.ProductService
+}
+
+// New returns the product handlers backed by products
+func New(products *service.ProductService) *Handler {
+	return &Handler{products: products}
 }
 
 // @Summary Get All Products
-// @Description Retrieve a list of all available products.
+// @Description Retrieve a page of available products, optionally filtered by price range and name and sorted.
 // @Tags Products
 // @Accept  json
 // @Produce  json
-// @Success 200 {object} map[string]interface{} "List of products retrieved successfully"
-// @Failure 500 {object} map[string]string "Internal Server Error"
+// @Param page query int false "Page number, starting at 1, up to 10000 rows deep"
+// @Param limit query int false "Page size, 1-100 (default 20)"
+// @Param after query string false "Cursor from pagination.next_cursor, replaces page and needs the same sort"
+// @Param min_price query number false "Minimum price"
+// @Param max_price query number false "Maximum price"
+// @Param name query string false "Case-insensitive name search"
+// @Param category_id query int false "Only products in this category or its subcategories"
+// @Param sort query string false "Sort order" Enums(price, -price, name, newest)
+// @Success 200 {object} ProductPage "List of products retrieved successfully"
+// @Failure 400 {object} apperror.Error "Invalid query parameters"
+// @Failure 500 {object} apperror.Error "Internal Server Error"
 // @Router /products [get]
-func GetAllProducts(c echo.Context) error {
-	// Query to fetch all products
-	query := "SELECT product_id, name, description, price FROM products"
-
-	rows, err := config.Pool.Query(context.Background(), query)
+func (h *Handler) GetAllProducts(c echo.Context) error {
+	// Parse paging, filtering and sorting options
+	filter, err := ParseProductFilter(c)
 	if err != nil {
-		return c.JSON(http.StatusInternalServerError, map[string]string{"message": "Failed to retrieve products"})
+		return apperror.BadRequest(err.Error())
 	}
-	defer rows.Close()
 
-	// Parse the results into a list of products
-	var products []Product
-	for rows.Next() {
-		var product Product
-		if err := rows.Scan(&product.ProductID, &product.Name, &product.Description, &product.Price); err != nil {
-			return c.JSON(http.StatusInternalServerError, map[string]string{"message": "Error scanning product data"})
-		}
-		products = append(products, product)
+	page, err := h.products.List(c.Request().Context(), filter)
+	if err != nil {
+		return err
 	}
 
-	return c.JSON(http.StatusOK, map[string]interface{}{"products": products})
+	return RespondProductPage(c, filter, page)
 }
 
 // @Summary Get Product by ID
@@ -53,23 +60,21 @@ func GetAllProducts(c echo.Context) error {
 // @Produce  json
 // @Param id path int true "Product ID"
 // @Success 200 {object} Product "Product retrieved successfully"
-// @Failure 404 {object} map[string]string "Product not found"
-// @Failure 500 {object} map[string]string "Internal Server Error"
+// @Failure 400 {object} apperror.Error "Invalid product ID"
+// @Failure 404 {object} apperror.Error "Product not found"
+// @Failure 500 {object} apperror.Error "Internal Server Error"
 // @Router /products/{id} [get]
-func GetProductByID(c echo.Context) error {
+func (h *Handler) GetProductByID(c echo.Context) error {
 	// Extract product ID from URL params
-	productID := c.Param("id")
-
-	// Query to fetch product by ID
-	query := "SELECT product_id, name, description, price FROM products WHERE product_id = $1"
-
-	var product Product
-	err := config.Pool.QueryRow(context.Background(), query, productID).
-		Scan(&product.ProductID, &product.Name, &product.Description, &product.Price)
+	productID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		return apperror.BadRequest("Invalid product ID")
+	}
 
+	product, err := h.products.Get(c.Request().Context(), productID)
 	if err != nil {
-		return c.JSON(http.StatusNotFound, map[string]string{"message": "Product not found"})
+		return err
 	}
 
 	return c.JSON(http.StatusOK, product)
-}
\ No newline at end of file
+}
@@ -0,0 +1,155 @@
+package handler
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"w4/lc3/internal/repository"
+
+	"github.com/labstack/echo/v4"
+)
+
+const (
+	defaultPageLimit = 20
+	maxPageLimit     = 100
+	maxPageOffset    = 10000 // rows skipped by page numbers, deeper lists page with after
+)
+
+// ProductFilter holds the paging, filtering and sorting options of a product list
+type ProductFilter = repository.ProductFilter
+
+// PageLinks holds ready-to-follow URLs for the current, next and previous page
+type PageLinks struct {
+	Self string `json:"self"`
+	Next string `json:"next,omitempty"`
+	Prev string `json:"prev,omitempty"`
+}
+
+// Pagination is the metadata returned alongside a page of products
+type Pagination struct {
+	Total      int       `json:"total"`
+	Page       int       `json:"page,omitempty"`
+	Limit      int       `json:"limit"`
+	NextCursor string    `json:"next_cursor,omitempty"`
+	Links      PageLinks `json:"links"`
+}
+
+// ProductPage is one page of a product list
+type ProductPage struct {
+	Products   []Product  `json:"products"`
+	Pagination Pagination `json:"pagination"`
+}
+
+// ParseProductFilter reads page, limit, after, min_price, max_price, name, category_id and sort from the query string
+func ParseProductFilter(c echo.Context) (ProductFilter, error) {
+	f := ProductFilter{Page: 1, Limit: defaultPageLimit}
+
+	if v := c.QueryParam("page"); v != "" {
+		page, err := strconv.Atoi(v)
+		if err != nil || page < 1 {
+			return f, errors.New("page must be a positive integer")
+		}
+		f.Page = page
+	}
+
+	if v := c.QueryParam("limit"); v != "" {
+		limit, err := strconv.Atoi(v)
+		if err != nil || limit < 1 || limit > maxPageLimit {
+			return f, fmt.Errorf("limit must be between 1 and %d", maxPageLimit)
+		}
+		f.Limit = limit
+	}
+
+	// checked before multiplying, a huge page would overflow the offset
+	if f.Page-1 > maxPageOffset/f.Limit {
+		return f, fmt.Errorf("page must be at most %d with limit %d, use after to go further", maxPageOffset/f.Limit+1, f.Limit)
+	}
+
+	for param, dst := range map[string]**float64{"min_price": &f.MinPrice, "max_price": &f.MaxPrice} {
+		if v := c.QueryParam(param); v != "" {
+			price, err := strconv.ParseFloat(v, 64)
+			if err != nil || price < 0 {
+				return f, fmt.Errorf("%s must be a non-negative number", param)
+			}
+			*dst = &price
+		}
+	}
+	if f.MinPrice != nil && f.MaxPrice != nil && *f.MinPrice > *f.MaxPrice {
+		return f, errors.New("min_price must not be greater than max_price")
+	}
+
+	f.Name = strings.TrimSpace(c.QueryParam("name"))
+
+	if v := c.QueryParam("category_id"); v != "" {
+		categoryID, err := strconv.Atoi(v)
+		if err != nil || categoryID < 1 {
+			return f, errors.New("category_id must be a positive integer")
+		}
+		f.CategoryID = &categoryID
+	}
+
+	f.Sort = c.QueryParam("sort")
+	if !repository.ValidSort(f.Sort) {
+		return f, errors.New("sort must be one of price, -price, name, newest")
+	}
+
+	// a cursor only continues the sort it was issued for
+	if v := c.QueryParam("after"); v != "" {
+		_, err := repository.DecodeCursorFor(v, f.Sort)
+		if errors.Is(err, repository.ErrCursorSort) {
+			return f, errors.New("after was issued for another sort, keep the sort of the first page")
+		}
+		if err != nil {
+			return f, errors.New("after is not a valid cursor")
+		}
+		f.After = v
+	}
+
+	return f, nil
+}
+
+// RespondProductPage writes a page of products with its pagination metadata and navigation links built from the current request URL
+func RespondProductPage(c echo.Context, f ProductFilter, list repository.ProductList) error {
+	page := ProductPage{Products: list.Products, Pagination: Pagination{Total: list.Total, Limit: f.Limit, NextCursor: list.NextCursor}}
+	if f.After == "" {
+		page.Pagination.Page = f.Page
+	}
+	page.Pagination.Links = pageLinks(c, f, list.NextCursor != "", list.NextCursor)
+	return c.JSON(http.StatusOK, page)
+}
+
+// pageLinks builds the self, next and prev links of a page from the current request URL.
+// In cursor mode the next link carries nextCursor, otherwise it moves the page number.
+func pageLinks(c echo.Context, f ProductFilter, hasNext bool, nextCursor string) PageLinks {
+	link := func(set map[string]string, del ...string) string {
+		u := *c.Request().URL
+		values := u.Query()
+		for _, k := range del {
+			values.Del(k)
+		}
+		for k, v := range set {
+			values.Set(k, v)
+		}
+		u.RawQuery = values.Encode()
+		return u.RequestURI()
+	}
+
+	links := PageLinks{Self: link(nil)}
+	if f.After != "" {
+		if hasNext {
+			links.Next = link(map[string]string{"after": nextCursor}, "page")
+		}
+		return links
+	}
+
+	if hasNext {
+		links.Next = link(map[string]string{"page": strconv.Itoa(f.Page + 1)})
+	}
+	if f.Page > 1 {
+		links.Prev = link(map[string]string{"page": strconv.Itoa(f.Page - 1)})
+	}
+	return links
+}
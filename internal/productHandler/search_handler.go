@@ -0,0 +1,66 @@
+package handler
+
+import (
+	"net/http"
+	"strings"
+
+	"w4/lc3/internal/apperror"
+	"w4/lc3/internal/repository"
+
+	"github.com/labstack/echo/v4"
+)
+
+// SearchHighlights holds the name and description with the matched terms marked
+type SearchHighlights = repository.SearchHighlights
+
+// SearchResult is a product matched by a full-text search
+type SearchResult = repository.SearchResult
+
+// SearchPage is one page of search results
+type SearchPage struct {
+	Query      string         `json:"query"`
+	Results    []SearchResult `json:"results"`
+	Pagination Pagination     `json:"pagination"`
+}
+
+// @Summary Search Products
+// @Description Full-text search over product name and description, ranked by relevance with highlighted snippets.
+// @Tags Products
+// @Accept  json
+// @Produce  json
+// @Param q query string true "Search terms, supports quoted phrases, OR and -exclusions"
+// @Param page query int false "Page number, starting at 1, up to 10000 rows deep"
+// @Param limit query int false "Page size, 1-100 (default 20)"
+// @Param min_price query number false "Minimum price"
+// @Param max_price query number false "Maximum price"
+// @Param category_id query int false "Only products in this category or its subcategories"
+// @Success 200 {object} SearchPage "Search results"
+// @Failure 400 {object} apperror.Error "Invalid query parameters"
+// @Failure 500 {object} apperror.Error "Internal Server Error"
+// @Router /products/search [get]
+func (h *Handler) SearchProducts(c echo.Context) error {
+	q := strings.TrimSpace(c.QueryParam("q"))
+	if q == "" {
+		return apperror.BadRequest("q is required")
+	}
+
+	// Reuse the product list options, results are always ordered by relevance
+	filter, err := ParseProductFilter(c)
+	if err != nil {
+		return apperror.BadRequest(err.Error())
+	}
+	if filter.After != "" || filter.Sort != "" {
+		return apperror.BadRequest("after and sort are not supported by search")
+	}
+
+	found, err := h.products.Search(c.Request().Context(), q, filter)
+	if err != nil {
+		return err
+	}
+	result := SearchPage{Query: q, Results: found.Results, Pagination: Pagination{Total: found.Total, Page: filter.Page, Limit: filter.Limit}}
+
+	hasNext := (filter.Page-1)*filter.Limit+len(result.Results) < result.Pagination.Total
+	result.Pagination.Links = pageLinks(c, filter, hasNext, "")
+
+	return c.JSON(http.StatusOK, result)
+}
@@ -0,0 +1,83 @@
+package handler
+
+import (
+	"net/http"
+	"strconv"
+
+	"w4/lc3/internal/apperror"
+	"w4/lc3/internal/auth"
+	"w4/lc3/internal/repository"
+
+	"github.com/labstack/echo/v4"
+)
+
+// AdjustStockRequest struct
+type AdjustStockRequest struct {
+	Delta  int    `json:"delta" validate:"required"`                                                 // Positive to add stock, negative to remove it
+	Reason string `json:"reason" validate:"required,oneof=restock damaged lost returned correction"` // Reason code of the adjustment
+	Note   string `json:"note"`                                                                      // Optional free-text note
+}
+
+// AdjustStockResponse struct
+type AdjustStockResponse struct {
+	Message   string `json:"message"`
+	ProductID int    `json:"product_id"`
+	Stock     int    `json:"stock"`
+}
+
+// @Summary Adjust Product Stock
+// @Description Add or remove stock for a product with a reason code. Admin only.
+// @Tags Products
+// @Accept  json
+// @Produce  json
+// @Param id path int true "Product ID"
+// @Param request body AdjustStockRequest true "Stock adjustment"
+// @Success 200 {object} AdjustStockResponse "Stock adjusted"
+// @Failure 400 {object} apperror.Error "Invalid request"
+// @Failure 401 {object} apperror.Error "Unauthorized"
+// @Failure 403 {object} apperror.Error "Forbidden"
+// @Failure 404 {object} apperror.Error "Product not found"
+// @Failure 409 {object} apperror.Error "Stock cannot go below zero"
+// @Failure 500 {object} apperror.Error "Internal Server Error"
+// @Router /admin/products/{id}/stock [post]
+func (h *Handler) AdjustStock(c echo.Context) error {
+	// Extract user ID from the authenticated principal
+	principal, err := auth.CurrentPrincipal(c)
+	if err != nil {
+		return err
+	}
+	userID := principal.UserID
+
+	// Extract product ID from URL params
+	productID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		return apperror.BadRequest("Invalid product ID")
+	}
+
+	// Parse request body
+	var req AdjustStockRequest
+	if err := c.Bind(&req); err != nil {
+		return apperror.BadRequest("Invalid request")
+	}
+	if err := c.Validate(&req); err != nil {
+		return err
+	}
+
+	// Stock can never go negative, every adjustment is recorded for auditing
+	stock, err := h.products.AdjustStock(c.Request().Context(), repository.StockAdjustment{
+		ProductID:  productID,
+		Delta:      req.Delta,
+		Reason:     req.Reason,
+		Note:       req.Note,
+		AdjustedBy: userID,
+	})
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(http.StatusOK, AdjustStockResponse{
+		Message:   "Stock adjusted",
+		ProductID: productID,
+		Stock:     stock,
+	})
+}
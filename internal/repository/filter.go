@@ -0,0 +1,71 @@
+package repository
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+)
+
+// ProductSorts are the sort options of a product list, the empty one orders by id
+var ProductSorts = []string{"", "price", "-price", "name", "newest"}
+
+// ProductFilter holds the paging, filtering and sorting options of a product list
+type ProductFilter struct {
+	Page       int
+	Limit      int
+	After      string // opaque cursor, switches to keyset paging when set
+	MinPrice   *float64
+	MaxPrice   *float64
+	Name       string
+	CategoryID *int // restricts the list to a category and all of its descendants
+	Sort       string
+}
+
+// ValidSort reports whether sort is one of ProductSorts
+func ValidSort(sort string) bool {
+	for _, s := range ProductSorts {
+		if s == sort {
+			return true
+		}
+	}
+	return false
+}
+
+// ErrCursorSort is returned for a cursor issued by a list with another sort, its key can't be compared
+var ErrCursorSort = errors.New("cursor belongs to another sort")
+
+// Cursor is the decoded form of ProductFilter.After, the sort, sort key and id of the last row of the previous page
+type Cursor struct {
+	Sort string `json:"s,omitempty"`
+	Key  string `json:"k,omitempty"`
+	ID   int    `json:"id"`
+}
+
+// EncodeCursor returns the opaque form of cur
+func EncodeCursor(cur Cursor) string {
+	data, _ := json.Marshal(cur)
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+// DecodeCursor reverses EncodeCursor
+func DecodeCursor(s string) (Cursor, error) {
+	var cur Cursor
+	data, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return cur, err
+	}
+	err = json.Unmarshal(data, &cur)
+	return cur, err
+}
+
+// DecodeCursorFor decodes a cursor of a list sorted by sort, ErrCursorSort when it was issued for another one
+func DecodeCursorFor(s, sort string) (Cursor, error) {
+	cur, err := DecodeCursor(s)
+	if err != nil {
+		return cur, err
+	}
+	if cur.Sort != sort {
+		return cur, ErrCursorSort
+	}
+	return cur, nil
+}
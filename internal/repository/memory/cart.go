@@ -0,0 +1,131 @@
+package memory
+
+import (
+	"context"
+	"sort"
+
+	"w4/lc3/internal/repository"
+)
+
+type cartRepo struct {
+	s *Store
+}
+
+// line joins a cart row with the current product details
+func (d *data) line(item repository.CartItem) repository.CartLine {
+	p := d.products[item.ProductID]
+	return repository.CartLine{
+		CartID:      item.CartID,
+		ProductID:   item.ProductID,
+		Name:        p.Name,
+		Description: p.Description,
+		UnitPrice:   p.Price,
+		Quantity:    item.Quantity,
+		Stock:       p.Stock,
+		CreatedAt:   item.CreatedAt,
+		Deleted:     p.deletedAt != nil,
+	}
+}
+
+func (r cartRepo) Lines(ctx context.Context, userID int) ([]repository.CartLine, error) {
+	defer r.s.lock()()
+
+	lines := []repository.CartLine{}
+	for _, item := range r.s.data.carts {
+		if item.UserID == userID {
+			lines = append(lines, r.s.data.line(item))
+		}
+	}
+	sort.Slice(lines, func(i, j int) bool { return lines[i].CartID < lines[j].CartID })
+	return lines, nil
+}
+
+// LinesForUpdate needs no row lock, a transaction already holds the whole store
+func (r cartRepo) LinesForUpdate(ctx context.Context, userID int) ([]repository.CartLine, error) {
+	lines, err := r.Lines(ctx, userID)
+	sort.Slice(lines, func(i, j int) bool { return lines[i].ProductID < lines[j].ProductID })
+	return lines, err
+}
+
+func (r cartRepo) Line(ctx context.Context, userID, cartID int) (repository.CartLine, error) {
+	defer r.s.lock()()
+
+	item, ok := r.s.data.carts[cartID]
+	if !ok || item.UserID != userID {
+		return repository.CartLine{}, repository.ErrNotFound
+	}
+	return r.s.data.line(item), nil
+}
+
+func (r cartRepo) Quantity(ctx context.Context, userID, productID int) (int, error) {
+	defer r.s.lock()()
+
+	if item, ok := r.s.data.cartItem(userID, productID); ok {
+		return item.Quantity, nil
+	}
+	return 0, nil
+}
+
+func (d *data) cartItem(userID, productID int) (repository.CartItem, bool) {
+	for _, item := range d.carts {
+		if item.UserID == userID && item.ProductID == productID {
+			return item, true
+		}
+	}
+	return repository.CartItem{}, false
+}
+
+func (r cartRepo) Add(ctx context.Context, userID, productID, quantity int) (repository.CartItem, error) {
+	defer r.s.lock()()
+
+	item, ok := r.s.data.cartItem(userID, productID)
+	if ok {
+		item.Quantity += quantity
+	} else {
+		item = repository.CartItem{
+			CartID:    r.s.data.next("carts"),
+			UserID:    userID,
+			ProductID: productID,
+			Quantity:  quantity,
+			CreatedAt: now(),
+		}
+	}
+	r.s.data.carts[item.CartID] = item
+	return item, nil
+}
+
+func (r cartRepo) SetQuantity(ctx context.Context, userID, cartID, quantity int) (repository.CartItem, error) {
+	defer r.s.lock()()
+
+	item, ok := r.s.data.carts[cartID]
+	if !ok || item.UserID != userID {
+		return repository.CartItem{}, repository.ErrNotFound
+	}
+	item.Quantity = quantity
+	r.s.data.carts[cartID] = item
+	return item, nil
+}
+
+func (r cartRepo) Remove(ctx context.Context, userID, cartID int) error {
+	defer r.s.lock()()
+
+	item, ok := r.s.data.carts[cartID]
+	if !ok || item.UserID != userID {
+		return repository.ErrNotFound
+	}
+	delete(r.s.data.carts, cartID)
+	return nil
+}
+
+func (r cartRepo) Clear(ctx context.Context, userID int) (int, error) {
+	defer r.s.lock()()
+
+	removed := 0
+	for id, item := range r.s.data.carts {
+		if item.UserID == userID {
+			delete(r.s.data.carts, id)
+			removed++
+		}
+	}
+	return removed, nil
+}
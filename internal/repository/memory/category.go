@@ -0,0 +1,73 @@
+package memory
+
+import (
+	"context"
+	"sort"
+
+	"w4/lc3/internal/repository"
+)
+
+type categoryRepo struct {
+	s *Store
+}
+
+func (r categoryRepo) List(ctx context.Context) ([]*repository.Category, error) {
+	defer r.s.lock()()
+
+	var categories []*repository.Category
+	for _, c := range r.s.data.categories {
+		c.Children = []*repository.Category{}
+		categories = append(categories, &c)
+	}
+	sort.Slice(categories, func(i, j int) bool {
+		if categories[i].Name != categories[j].Name {
+			return categories[i].Name < categories[j].Name
+		}
+		return categories[i].CategoryID < categories[j].CategoryID
+	})
+	return categories, nil
+}
+
+func (r categoryRepo) Get(ctx context.Context, categoryID int) (repository.Category, error) {
+	defer r.s.lock()()
+
+	c, ok := r.s.data.categories[categoryID]
+	if !ok {
+		return repository.Category{}, repository.ErrNotFound
+	}
+	c.Children = []*repository.Category{}
+	return c, nil
+}
+
+// AddCategory creates a category under parentID, or a root one when parentID is nil.
+// The API has no endpoint creating categories, this is how the store is filled.
+func (s *Store) AddCategory(name, slug string, parentID *int) repository.Category {
+	defer s.lock()()
+
+	c := repository.Category{CategoryID: s.data.next("categories"), Name: name, Slug: slug, ParentID: parentID}
+	s.data.categories[c.CategoryID] = c
+	return c
+}
+
+// Categorize files a product under the given categories
+func (s *Store) Categorize(productID int, categoryIDs ...int) {
+	defer s.lock()()
+
+	linked := append([]int(nil), s.data.productCategories[productID]...)
+	s.data.productCategories[productID] = append(linked, categoryIDs...)
+}
+
+// categorySubtree returns the ids of a category and all of its descendants
+func (d *data) categorySubtree(categoryID int) map[int]bool {
+	tree := map[int]bool{categoryID: true}
+	for grew := true; grew; {
+		grew = false
+		for id, c := range d.categories {
+			if c.ParentID != nil && tree[*c.ParentID] && !tree[id] {
+				tree[id] = true
+				grew = true
+			}
+		}
+	}
+	return tree
+}
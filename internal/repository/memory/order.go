@@ -0,0 +1,112 @@
+package memory
+
+import (
+	"context"
+	"sort"
+
+	"w4/lc3/internal/pricing"
+	"w4/lc3/internal/repository"
+)
+
+type orderRepo struct {
+	s *Store
+}
+
+func (r orderRepo) List(ctx context.Context, userID int) ([]repository.Order, error) {
+	defer r.s.lock()()
+
+	orders := []repository.Order{}
+	for _, order := range r.s.data.orders {
+		if order.UserID == userID {
+			orders = append(orders, order)
+		}
+	}
+	sort.Slice(orders, func(i, j int) bool { return orders[i].OrderID < orders[j].OrderID })
+	return orders, nil
+}
+
+func (r orderRepo) Get(ctx context.Context, orderID int) (repository.Order, error) {
+	defer r.s.lock()()
+
+	order, ok := r.s.data.orders[orderID]
+	if !ok {
+		return repository.Order{}, repository.ErrNotFound
+	}
+	return order, nil
+}
+
+// GetForUpdate needs no row lock, a transaction already holds the whole store
+func (r orderRepo) GetForUpdate(ctx context.Context, orderID int) (repository.Order, error) {
+	return r.Get(ctx, orderID)
+}
+
+func (r orderRepo) Items(ctx context.Context, orderID int) ([]repository.OrderItem, error) {
+	defer r.s.lock()()
+
+	items := []repository.OrderItem{}
+	for _, item := range r.s.data.orderItems {
+		if item.orderID == orderID {
+			// the name is the current one, the price the snapshot taken at checkout
+			item.Name = r.s.data.products[item.ProductID].Name
+			item.LineTotal = pricing.LineTotal(pricing.Line{ProductID: item.ProductID, UnitPrice: item.UnitPrice, Quantity: item.Quantity})
+			items = append(items, item.OrderItem)
+		}
+	}
+	sort.Slice(items, func(i, j int) bool { return items[i].OrderItemID < items[j].OrderItemID })
+	return items, nil
+}
+
+func (r orderRepo) History(ctx context.Context, orderID int) ([]repository.StatusChange, error) {
+	defer r.s.lock()()
+
+	// history is appended in order, so it is already oldest first
+	history := []repository.StatusChange{}
+	for _, change := range r.s.data.history {
+		if change.OrderID == orderID {
+			history = append(history, change)
+		}
+	}
+	return history, nil
+}
+
+func (r orderRepo) Create(ctx context.Context, userID int, total float64) (repository.Order, error) {
+	defer r.s.lock()()
+
+	order := repository.Order{
+		OrderID:    r.s.data.next("orders"),
+		UserID:     userID,
+		TotalPrice: total,
+		Status:     repository.StatusPending,
+		CreatedAt:  now(),
+	}
+	r.s.data.orders[order.OrderID] = order
+	return order, nil
+}
+
+func (r orderRepo) AddItem(ctx context.Context, orderID int, item repository.OrderItem) error {
+	defer r.s.lock()()
+
+	item.OrderItemID = r.s.data.next("orderitems")
+	r.s.data.orderItems[item.OrderItemID] = orderItem{OrderItem: item, orderID: orderID}
+	return nil
+}
+
+func (r orderRepo) SetStatus(ctx context.Context, orderID int, status string) error {
+	defer r.s.lock()()
+
+	order, ok := r.s.data.orders[orderID]
+	if !ok {
+		return repository.ErrNotFound
+	}
+	order.Status = status
+	r.s.data.orders[orderID] = order
+	return nil
+}
+
+func (r orderRepo) RecordStatusChange(ctx context.Context, change repository.StatusChange) error {
+	defer r.s.lock()()
+
+	change.ChangedAt = now()
+	r.s.data.history = append(r.s.data.history, change)
+	return nil
+}
@@ -0,0 +1,248 @@
+package memory
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"w4/lc3/internal/repository"
+)
+
+type productRepo struct {
+	s *Store
+}
+
+// productOrder compares two products under a sort option, the id breaks ties in the direction of the key
+func productOrder(sortBy string) (func(a, b product) int, error) {
+	var key func(a, b product) int
+	desc := false
+	switch sortBy {
+	case "":
+		key = func(a, b product) int { return 0 }
+	case "price", "-price":
+		key = func(a, b product) int { return compareFloat(a.Price, b.Price) }
+		desc = sortBy == "-price"
+	case "name":
+		key = func(a, b product) int { return strings.Compare(a.Name, b.Name) }
+	case "newest":
+		key = func(a, b product) int { return a.createdAt.Compare(b.createdAt) }
+		desc = true
+	default:
+		return nil, fmt.Errorf("unknown sort %q", sortBy)
+	}
+
+	return func(a, b product) int {
+		c := key(a, b)
+		if c == 0 {
+			c = a.ProductID - b.ProductID
+		}
+		if desc {
+			return -c
+		}
+		return c
+	}, nil
+}
+
+// sortKey is the cursor form of the sort key of p
+func sortKey(sortBy string, p product) string {
+	switch sortBy {
+	case "price", "-price":
+		return strconv.FormatFloat(p.Price, 'f', -1, 64)
+	case "name":
+		return p.Name
+	case "newest":
+		return p.createdAt.Format(time.RFC3339Nano)
+	}
+	return ""
+}
+
+// cursorProduct rebuilds the sort key and id a cursor points at
+func cursorProduct(sortBy string, cur repository.Cursor) (product, error) {
+	p := product{Product: repository.Product{ProductID: cur.ID}}
+	var err error
+	switch sortBy {
+	case "price", "-price":
+		p.Price, err = strconv.ParseFloat(cur.Key, 64)
+	case "name":
+		p.Name = cur.Key
+	case "newest":
+		p.createdAt, err = time.Parse(time.RFC3339Nano, cur.Key)
+	}
+	return p, err
+}
+
+func compareFloat(a, b float64) int {
+	switch {
+	case a < b:
+		return -1
+	case a > b:
+		return 1
+	}
+	return 0
+}
+
+// matching returns the non-deleted products passing the price, name and category conditions of f
+func (d *data) matching(f repository.ProductFilter) []product {
+	var inCategory map[int]bool
+	if f.CategoryID != nil {
+		inCategory = d.categorySubtree(*f.CategoryID)
+	}
+
+	var matches []product
+	for _, p := range d.products {
+		switch {
+		case p.deletedAt != nil,
+			f.MinPrice != nil && p.Price < *f.MinPrice,
+			f.MaxPrice != nil && p.Price > *f.MaxPrice,
+			f.Name != "" && !strings.Contains(strings.ToLower(p.Name), strings.ToLower(f.Name)):
+			continue
+		}
+		if inCategory != nil && !d.inAny(p.ProductID, inCategory) {
+			continue
+		}
+		matches = append(matches, p)
+	}
+	return matches
+}
+
+func (d *data) inAny(productID int, categories map[int]bool) bool {
+	for _, categoryID := range d.productCategories[productID] {
+		if categories[categoryID] {
+			return true
+		}
+	}
+	return false
+}
+
+func (r productRepo) List(ctx context.Context, f repository.ProductFilter) (repository.ProductList, error) {
+	defer r.s.lock()()
+
+	page := repository.ProductList{Products: []repository.Product{}}
+	order, err := productOrder(f.Sort)
+	if err != nil {
+		return page, err
+	}
+
+	matches := r.s.data.matching(f)
+	page.Total = len(matches)
+	sort.Slice(matches, func(i, j int) bool { return order(matches[i], matches[j]) < 0 })
+
+	// Keyset paging continues strictly after the row encoded in the cursor
+	if f.After != "" {
+		cur, err := repository.DecodeCursorFor(f.After, f.Sort)
+		if err != nil {
+			return page, err
+		}
+		after, err := cursorProduct(f.Sort, cur)
+		if err != nil {
+			return page, err
+		}
+		start := sort.Search(len(matches), func(i int) bool { return order(matches[i], after) > 0 })
+		matches = matches[start:]
+	} else {
+		offset := min((f.Page-1)*f.Limit, len(matches))
+		matches = matches[offset:]
+	}
+
+	if len(matches) > f.Limit {
+		last := matches[f.Limit-1]
+		page.NextCursor = repository.EncodeCursor(repository.Cursor{Sort: f.Sort, Key: sortKey(f.Sort, last), ID: last.ProductID})
+		matches = matches[:f.Limit]
+	}
+	for _, p := range matches {
+		page.Products = append(page.Products, p.Product)
+	}
+	return page, nil
+}
+
+func (r productRepo) Search(ctx context.Context, query string, f repository.ProductFilter) (repository.SearchList, error) {
+	defer r.s.lock()()
+
+	result := repository.SearchList{Results: []repository.SearchResult{}}
+	q := parseSearch(query)
+	for _, p := range r.s.data.matching(f) {
+		if res, ok := q.match(p.Product); ok {
+			result.Results = append(result.Results, res)
+		}
+	}
+	result.Total = len(result.Results)
+
+	sort.Slice(result.Results, func(i, j int) bool {
+		a, b := result.Results[i], result.Results[j]
+		if a.Score != b.Score {
+			return a.Score > b.Score
+		}
+		return a.ProductID < b.ProductID
+	})
+
+	offset := min((f.Page-1)*f.Limit, len(result.Results))
+	result.Results = result.Results[offset:min(offset+f.Limit, len(result.Results))]
+	return result, nil
+}
+
+func (r productRepo) Get(ctx context.Context, productID int) (repository.Product, error) {
+	defer r.s.lock()()
+
+	p, ok := r.s.data.products[productID]
+	if !ok || p.deletedAt != nil {
+		return repository.Product{}, repository.ErrNotFound
+	}
+	return p.Product, nil
+}
+
+func (r productRepo) Create(ctx context.Context, p repository.Product) (repository.Product, error) {
+	defer r.s.lock()()
+
+	p.ProductID = r.s.data.next("products")
+	r.s.data.products[p.ProductID] = product{Product: p, createdAt: now()}
+	return p, nil
+}
+
+func (r productRepo) Update(ctx context.Context, p repository.Product) (repository.Product, error) {
+	defer r.s.lock()()
+
+	stored, ok := r.s.data.products[p.ProductID]
+	if !ok || stored.deletedAt != nil {
+		return repository.Product{}, repository.ErrNotFound
+	}
+	stored.Name, stored.Description, stored.Price = p.Name, p.Description, p.Price
+	r.s.data.products[p.ProductID] = stored
+	return stored.Product, nil
+}
+
+func (r productRepo) Delete(ctx context.Context, productID int) error {
+	defer r.s.lock()()
+
+	stored, ok := r.s.data.products[productID]
+	if !ok || stored.deletedAt != nil {
+		return repository.ErrNotFound
+	}
+	stored.deletedAt = timePtr(now())
+	r.s.data.products[productID] = stored
+	return nil
+}
+
+func (r productRepo) AdjustStock(ctx context.Context, productID, delta int) (int, error) {
+	defer r.s.lock()()
+
+	stored, ok := r.s.data.products[productID]
+	if !ok {
+		return 0, repository.ErrNotFound
+	}
+	if stored.Stock+delta < 0 {
+		return 0, repository.ErrInsufficientStock
+	}
+	stored.Stock += delta
+	r.s.data.products[productID] = stored
+	return stored.Stock, nil
+}
+
+func (r productRepo) RecordStockAdjustment(ctx context.Context, adj repository.StockAdjustment) error {
+	defer r.s.lock()()
+
+	r.s.data.stockAdjustments = append(r.s.data.stockAdjustments, adj)
+	return nil
+}
@@ -0,0 +1,135 @@
+package memory
+
+import (
+	"regexp"
+	"strings"
+
+	"w4/lc3/internal/repository"
+)
+
+// Weights of a match in the name and in the description, the A and B weights of ts_rank
+const (
+	nameWeight        = 1.0
+	descriptionWeight = 0.4
+)
+
+var (
+	searchToken = regexp.MustCompile(`-?"[^"]*"?|\S+`)
+	wordPattern = regexp.MustCompile(`[\p{L}\p{N}]+`)
+)
+
+// searchQuery is a websearch query: alternatives separated by OR, each a set of
+// required and excluded terms. A term is one word or a quoted phrase.
+type searchQuery struct {
+	alternatives []searchAlternative
+}
+
+type searchAlternative struct {
+	include [][]string
+	exclude [][]string
+}
+
+// parseSearch reads the websearch_to_tsquery syntax: words, "quoted phrases", OR and -exclusions
+func parseSearch(query string) searchQuery {
+	q := searchQuery{alternatives: []searchAlternative{{}}}
+	for _, token := range searchToken.FindAllString(query, -1) {
+		if strings.EqualFold(token, "or") {
+			q.alternatives = append(q.alternatives, searchAlternative{})
+			continue
+		}
+		alt := &q.alternatives[len(q.alternatives)-1]
+		exclude := strings.HasPrefix(token, "-")
+		term := words(strings.TrimPrefix(token, "-"))
+		if len(term) == 0 {
+			continue
+		}
+		if exclude {
+			alt.exclude = append(alt.exclude, term)
+		} else {
+			alt.include = append(alt.include, term)
+		}
+	}
+	return q
+}
+
+// match scores p against the query and marks the matched words
+func (q searchQuery) match(p repository.Product) (repository.SearchResult, bool) {
+	name, description := words(p.Name), words(p.Description)
+
+	var matched [][]string
+	for _, alt := range q.alternatives {
+		if len(alt.include) == 0 || !alt.matches(name, description) {
+			continue
+		}
+		matched = append(matched, alt.include...)
+	}
+	if len(matched) == 0 {
+		return repository.SearchResult{}, false
+	}
+
+	res := repository.SearchResult{Product: p}
+	marked := map[string]bool{}
+	for _, term := range matched {
+		res.Score += nameWeight*float64(count(name, term)) + descriptionWeight*float64(count(description, term))
+		for _, w := range term {
+			marked[stem(w)] = true
+		}
+	}
+	res.Highlights.Name = highlight(p.Name, marked)
+	res.Highlights.Description = highlight(p.Description, marked)
+	return res, true
+}
+
+func (alt searchAlternative) matches(name, description []string) bool {
+	for _, term := range alt.include {
+		if count(name, term)+count(description, term) == 0 {
+			return false
+		}
+	}
+	for _, term := range alt.exclude {
+		if count(name, term)+count(description, term) > 0 {
+			return false
+		}
+	}
+	return true
+}
+
+// count is how many times the phrase term appears in text, comparing stems
+func count(text, term []string) int {
+	n := 0
+	for i := 0; i+len(term) <= len(text); i++ {
+		found := true
+		for j, w := range term {
+			if stem(text[i+j]) != stem(w) {
+				found = false
+				break
+			}
+		}
+		if found {
+			n++
+		}
+	}
+	return n
+}
+
+// highlight wraps the words of text whose stem was matched in <mark> tags, like ts_headline
+func highlight(text string, marked map[string]bool) string {
+	return wordPattern.ReplaceAllStringFunc(text, func(w string) string {
+		if marked[stem(strings.ToLower(w))] {
+			return "<mark>" + w + "</mark>"
+		}
+		return w
+	})
+}
+
+func words(s string) []string {
+	return wordPattern.FindAllString(strings.ToLower(s), -1)
+}
+
+// stem is a crude stand-in for the english stemmer, it only folds plurals
+func stem(w string) string {
+	if len(w) > 2 && strings.HasSuffix(w, "s") && !strings.HasSuffix(w, "ss") {
+		return strings.TrimSuffix(w, "s")
+	}
+	return w
+}
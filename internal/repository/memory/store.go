@@ -0,0 +1,160 @@
+// Package memory implements the repositories in process memory, for tests and running the API without a database.
+// It follows the behaviour of the postgres package, so both can back the same test suite.
+package memory
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"w4/lc3/internal/repository"
+)
+
+var _ repository.Store = (*Store)(nil)
+
+// Store implements repository.Store on maps guarded by one mutex.
+// A transaction holds the mutex from start to end and restores a snapshot of the data when it fails.
+type Store struct {
+	mu   *sync.Mutex
+	data *data
+	inTx bool
+}
+
+type product struct {
+	repository.Product
+	createdAt time.Time
+	deletedAt *time.Time
+}
+
+type orderItem struct {
+	repository.OrderItem
+	orderID int
+}
+
+type resetToken struct {
+	userID    int
+	hash      string
+	expiresAt time.Time
+	used      bool
+}
+
+type refreshToken struct {
+	repository.RefreshToken
+	expiresAt time.Time
+	used      bool
+	revoked   bool
+}
+
+// data is everything the store holds, copied as a whole when a transaction starts
+type data struct {
+	seq map[string]int // last id handed out per table
+
+	products          map[int]product
+	stockAdjustments  []repository.StockAdjustment
+	categories        map[int]repository.Category
+	productCategories map[int][]int // product id to category ids
+	carts             map[int]repository.CartItem
+	orders            map[int]repository.Order
+	orderItems        map[int]orderItem
+	history           []repository.StatusChange
+	users             map[int]repository.User
+	refreshTokens     map[int]refreshToken
+	revokedTokens     map[string]time.Time
+	resetTokens       map[int]resetToken
+}
+
+// New returns an empty store
+func New() *Store {
+	return &Store{
+		mu: &sync.Mutex{},
+		data: &data{
+			seq:               map[string]int{},
+			products:          map[int]product{},
+			categories:        map[int]repository.Category{},
+			productCategories: map[int][]int{},
+			carts:             map[int]repository.CartItem{},
+			orders:            map[int]repository.Order{},
+			orderItems:        map[int]orderItem{},
+			users:             map[int]repository.User{},
+			refreshTokens:     map[int]refreshToken{},
+			revokedTokens:     map[string]time.Time{},
+			resetTokens:       map[int]resetToken{},
+		},
+	}
+}
+
+func (s *Store) Products() repository.ProductRepository    { return productRepo{s} }
+func (s *Store) Categories() repository.CategoryRepository { return categoryRepo{s} }
+func (s *Store) Carts() repository.CartRepository          { return cartRepo{s} }
+func (s *Store) Orders() repository.OrderRepository        { return orderRepo{s} }
+func (s *Store) Users() repository.UserRepository          { return userRepo{s} }
+func (s *Store) Tokens() repository.TokenRepository        { return tokenRepo{s} }
+
+// WithTx runs fn with the store locked, undoing every change fn made when it returns an error
+func (s *Store) WithTx(ctx context.Context, fn func(tx repository.Store) error) error {
+	if s.inTx {
+		return fn(s)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	snapshot := s.data.clone()
+	if err := fn(&Store{mu: s.mu, data: s.data, inTx: true}); err != nil {
+		*s.data = *snapshot
+		return err
+	}
+	return nil
+}
+
+// lock guards a single repository call, inside a transaction the mutex is already held
+func (s *Store) lock() func() {
+	if s.inTx {
+		return func() {}
+	}
+	s.mu.Lock()
+	return s.mu.Unlock
+}
+
+// next returns the next id of a table, like a SERIAL column
+func (d *data) next(table string) int {
+	d.seq[table]++
+	return d.seq[table]
+}
+
+func (d *data) clone() *data {
+	c := &data{
+		seq:               copyMap(d.seq),
+		products:          copyMap(d.products),
+		stockAdjustments:  append([]repository.StockAdjustment(nil), d.stockAdjustments...),
+		categories:        copyMap(d.categories),
+		productCategories: copyMap(d.productCategories),
+		carts:             copyMap(d.carts),
+		orders:            copyMap(d.orders),
+		orderItems:        copyMap(d.orderItems),
+		history:           append([]repository.StatusChange(nil), d.history...),
+		users:             copyMap(d.users),
+		refreshTokens:     copyMap(d.refreshTokens),
+		revokedTokens:     copyMap(d.revokedTokens),
+		resetTokens:       copyMap(d.resetTokens),
+	}
+	return c
+}
+
+// copyMap copies the entries of m, values are replaced rather than modified in place so a shallow copy is enough
+func copyMap[K comparable, V any](m map[K]V) map[K]V {
+	c := make(map[K]V, len(m))
+	for k, v := range m {
+		c[k] = v
+	}
+	return c
+}
+
+// now is the time the store records, truncated like a database timestamp
+func now() time.Time {
+	return time.Now().UTC().Truncate(time.Microsecond)
+}
+
+func timePtr(t time.Time) *time.Time {
+	return &t
+}
@@ -0,0 +1,151 @@
+package memory
+
+import (
+	"context"
+	"time"
+
+	"w4/lc3/internal/repository"
+)
+
+type tokenRepo struct {
+	s *Store
+}
+
+func (r tokenRepo) CreateRefreshToken(ctx context.Context, t repository.RefreshToken, ttl time.Duration) error {
+	defer r.s.lock()()
+
+	t.ID = r.s.data.next("refreshtokens")
+	r.s.data.refreshTokens[t.ID] = refreshToken{RefreshToken: t, expiresAt: now().Add(ttl)}
+	return nil
+}
+
+// RefreshTokenForUpdate needs no row lock, a transaction already holds the whole store
+func (r tokenRepo) RefreshTokenForUpdate(ctx context.Context, hash string) (repository.RefreshToken, error) {
+	defer r.s.lock()()
+
+	for _, t := range r.s.data.refreshTokens {
+		if t.Hash == hash {
+			found := t.RefreshToken
+			found.Spent = t.used || t.revoked
+			found.Expired = !t.expiresAt.After(now())
+			return found, nil
+		}
+	}
+	return repository.RefreshToken{}, repository.ErrNotFound
+}
+
+func (r tokenRepo) MarkRefreshTokenUsed(ctx context.Context, tokenID int) error {
+	defer r.s.lock()()
+
+	if t, ok := r.s.data.refreshTokens[tokenID]; ok {
+		t.used = true
+		r.s.data.refreshTokens[tokenID] = t
+	}
+	return nil
+}
+
+// revokeWhere revokes every refresh token match accepts
+func (d *data) revokeWhere(match func(t refreshToken) bool) {
+	for id, t := range d.refreshTokens {
+		if match(t) {
+			t.revoked = true
+			d.refreshTokens[id] = t
+		}
+	}
+}
+
+func (r tokenRepo) RevokeFamily(ctx context.Context, familyID string) error {
+	defer r.s.lock()()
+
+	r.s.data.revokeWhere(func(t refreshToken) bool { return t.FamilyID == familyID })
+	return nil
+}
+
+func (r tokenRepo) RevokeFamilyOf(ctx context.Context, userID int, hash string) error {
+	defer r.s.lock()()
+
+	for _, t := range r.s.data.refreshTokens {
+		if t.Hash == hash {
+			r.s.data.revokeWhere(func(other refreshToken) bool { return other.FamilyID == t.FamilyID && other.UserID == userID })
+			break
+		}
+	}
+	return nil
+}
+
+func (r tokenRepo) RevokeUserSessions(ctx context.Context, userID int) error {
+	defer r.s.lock()()
+
+	r.s.data.revokeWhere(func(t refreshToken) bool { return t.UserID == userID })
+	return nil
+}
+
+func (r tokenRepo) RevokeAccessToken(ctx context.Context, jti string, expiresAt time.Time) error {
+	defer r.s.lock()()
+
+	if _, ok := r.s.data.revokedTokens[jti]; !ok {
+		r.s.data.revokedTokens[jti] = expiresAt
+	}
+	return nil
+}
+
+func (r tokenRepo) IsAccessTokenRevoked(ctx context.Context, jti string) (bool, error) {
+	defer r.s.lock()()
+
+	_, revoked := r.s.data.revokedTokens[jti]
+	return revoked, nil
+}
+
+func (r tokenRepo) PurgeRevokedAccessTokens(ctx context.Context) error {
+	defer r.s.lock()()
+
+	for jti, expiresAt := range r.s.data.revokedTokens {
+		if expiresAt.Before(now()) {
+			delete(r.s.data.revokedTokens, jti)
+		}
+	}
+	return nil
+}
+
+func (r tokenRepo) CreateResetToken(ctx context.Context, userID int, hash string, ttl time.Duration) error {
+	defer r.s.lock()()
+
+	r.s.data.resetTokens[r.s.data.next("passwordresettokens")] = resetToken{userID: userID, hash: hash, expiresAt: now().Add(ttl)}
+	return nil
+}
+
+func (r tokenRepo) SpendResetTokens(ctx context.Context, userID int) error {
+	defer r.s.lock()()
+
+	for id, t := range r.s.data.resetTokens {
+		if t.userID == userID {
+			t.used = true
+			r.s.data.resetTokens[id] = t
+		}
+	}
+	return nil
+}
+
+func (r tokenRepo) UseResetToken(ctx context.Context, hash string) (int, error) {
+	defer r.s.lock()()
+
+	for id, t := range r.s.data.resetTokens {
+		if t.hash == hash && !t.used && t.expiresAt.After(now()) {
+			t.used = true
+			r.s.data.resetTokens[id] = t
+			return t.userID, nil
+		}
+	}
+	return 0, repository.ErrNotFound
+}
+
+func (r tokenRepo) DeleteResetTokens(ctx context.Context, userID int) error {
+	defer r.s.lock()()
+
+	for id, t := range r.s.data.resetTokens {
+		if t.userID == userID {
+			delete(r.s.data.resetTokens, id)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,127 @@
+package memory
+
+import (
+	"context"
+	"time"
+
+	"w4/lc3/internal/repository"
+)
+
+type userRepo struct {
+	s *Store
+}
+
+// emailTaken reports whether an account other than userID uses email, deleted ones included like the unique index
+func (d *data) emailTaken(email string, userID int) bool {
+	for _, u := range d.users {
+		if u.Email == email && u.ID != userID {
+			return true
+		}
+	}
+	return false
+}
+
+func (r userRepo) Create(ctx context.Context, u repository.User) (repository.User, error) {
+	defer r.s.lock()()
+
+	if r.s.data.emailTaken(u.Email, 0) {
+		return repository.User{}, repository.ErrEmailTaken
+	}
+	u.ID = r.s.data.next("users")
+	r.s.data.users[u.ID] = u
+	return u, nil
+}
+
+func (r userRepo) Get(ctx context.Context, userID int) (repository.User, error) {
+	defer r.s.lock()()
+
+	u, ok := r.s.data.users[userID]
+	if !ok || u.DeletedAt != nil {
+		return repository.User{}, repository.ErrNotFound
+	}
+	return u, nil
+}
+
+// GetForUpdate needs no row lock, a transaction already holds the whole store
+func (r userRepo) GetForUpdate(ctx context.Context, userID int) (repository.User, error) {
+	return r.Get(ctx, userID)
+}
+
+func (r userRepo) GetByEmail(ctx context.Context, email string) (repository.User, error) {
+	defer r.s.lock()()
+
+	for _, u := range r.s.data.users {
+		if u.Email == email && u.DeletedAt == nil {
+			return u, nil
+		}
+	}
+	return repository.User{}, repository.ErrNotFound
+}
+
+func (r userRepo) Update(ctx context.Context, u repository.User) error {
+	defer r.s.lock()()
+
+	if _, ok := r.s.data.users[u.ID]; !ok {
+		return repository.ErrNotFound
+	}
+	if r.s.data.emailTaken(u.Email, u.ID) {
+		return repository.ErrEmailTaken
+	}
+	r.s.data.users[u.ID] = u
+	return nil
+}
+
+func (r userRepo) MarkVerified(ctx context.Context, userID int, email string) error {
+	defer r.s.lock()()
+
+	u, ok := r.s.data.users[userID]
+	if !ok || u.Email != email {
+		return repository.ErrNotFound
+	}
+	if u.EmailVerifiedAt == nil {
+		u.EmailVerifiedAt = timePtr(now())
+		r.s.data.users[userID] = u
+	}
+	return nil
+}
+
+func (r userRepo) RestartVerification(ctx context.Context, userID int) error {
+	defer r.s.lock()()
+
+	u, ok := r.s.data.users[userID]
+	if !ok {
+		return repository.ErrNotFound
+	}
+	u.EmailVerifiedAt = nil
+	u.VerificationSentAt = timePtr(now())
+	r.s.data.users[userID] = u
+	return nil
+}
+
+func (r userRepo) ClaimVerificationSend(ctx context.Context, userID int, wait time.Duration) (repository.User, error) {
+	defer r.s.lock()()
+
+	u, ok := r.s.data.users[userID]
+	switch {
+	case !ok, u.DeletedAt != nil, u.EmailVerifiedAt != nil,
+		u.VerificationSentAt != nil && u.VerificationSentAt.After(now().Add(-wait)):
+		return repository.User{}, repository.ErrNotFound
+	}
+	u.VerificationSentAt = timePtr(now())
+	r.s.data.users[userID] = u
+	return u, nil
+}
+
+func (r userRepo) VerificationCooldown(ctx context.Context, userID int, wait time.Duration) (bool, time.Duration, error) {
+	defer r.s.lock()()
+
+	u, ok := r.s.data.users[userID]
+	if !ok || u.DeletedAt != nil {
+		return false, 0, repository.ErrNotFound
+	}
+	var remaining time.Duration
+	if u.VerificationSentAt != nil {
+		remaining = time.Until(u.VerificationSentAt.Add(wait))
+	}
+	return u.EmailVerifiedAt != nil, remaining, nil
+}
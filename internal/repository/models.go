@@ -0,0 +1,140 @@
+package repository
+
+import "time"
+
+// Order statuses
+const (
+	StatusPending   = "pending"
+	StatusPaid      = "paid"
+	StatusShipped   = "shipped"
+	StatusDelivered = "delivered"
+	StatusCancelled = "cancelled"
+)
+
+// Product is an entry of the catalog
+type Product struct {
+	ProductID   int     `json:"product_id"`
+	Name        string  `json:"name"`
+	Description string  `json:"description"`
+	Price       float64 `json:"price"`
+	Stock       int     `json:"stock"`
+}
+
+// ProductList is one page of a product list and the number of products matching overall
+type ProductList struct {
+	Products   []Product
+	Total      int
+	NextCursor string // set in cursor mode when there is a next page
+}
+
+// SearchHighlights holds the name and description with the matched terms marked
+type SearchHighlights struct {
+	Name        string `json:"name"`
+	Description string `json:"description"`
+}
+
+// SearchResult is a product matched by a full-text search
+type SearchResult struct {
+	Product
+	Score      float64          `json:"score"`
+	Highlights SearchHighlights `json:"highlights"`
+}
+
+// SearchList is one page of search results, ordered by relevance
+type SearchList struct {
+	Results []SearchResult
+	Total   int
+}
+
+// StockAdjustment is one audited change of a product's stock
+type StockAdjustment struct {
+	ProductID  int
+	Delta      int
+	Reason     string
+	Note       string
+	AdjustedBy int
+}
+
+// Category struct, Children is filled when the categories are returned as a tree
+type Category struct {
+	CategoryID int         `json:"category_id"`
+	Name       string      `json:"name"`
+	Slug       string      `json:"slug"`
+	ParentID   *int        `json:"parent_id"`
+	Children   []*Category `json:"children"`
+}
+
+// CartItem is one row of a user's cart
+type CartItem struct {
+	CartID    int       `json:"cart_id"`
+	UserID    int       `json:"user_id"`
+	ProductID int       `json:"product_id"`
+	Quantity  int       `json:"quantity"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// CartLine is a cart item enriched with the current product details
+type CartLine struct {
+	CartID       int       `json:"cart_id"`
+	ProductID    int       `json:"product_id"`
+	Name         string    `json:"name"`
+	Description  string    `json:"description"`
+	UnitPrice    float64   `json:"unit_price"`
+	Quantity     int       `json:"quantity"`
+	LineSubtotal float64   `json:"line_subtotal"`
+	Stock        int       `json:"stock"`
+	Available    bool      `json:"available"` // false when the product was removed or stock is below quantity
+	CreatedAt    time.Time `json:"created_at"`
+	Deleted      bool      `json:"-"` // the product was removed from the catalog
+}
+
+// Order is the header of an order
+type Order struct {
+	OrderID    int       `json:"order_id"`
+	UserID     int       `json:"user_id"`
+	TotalPrice float64   `json:"total_price"`
+	Status     string    `json:"status"`
+	CreatedAt  time.Time `json:"created_at"`
+}
+
+// OrderItem is one line of an order with the price paid at checkout
+type OrderItem struct {
+	OrderItemID int     `json:"order_item_id"`
+	ProductID   int     `json:"product_id"`
+	Name        string  `json:"name"`
+	UnitPrice   float64 `json:"unit_price"`
+	Quantity    int     `json:"quantity"`
+	LineTotal   float64 `json:"line_total"`
+}
+
+// StatusChange is one entry of an order's status history
+type StatusChange struct {
+	OrderID    int       `json:"-"`
+	FromStatus *string   `json:"from_status"`
+	ToStatus   string    `json:"to_status"`
+	ChangedBy  *int      `json:"changed_by"`
+	ChangedAt  time.Time `json:"changed_at"`
+}
+
+// User is an account. Deleted accounts are anonymised and keep their row for the orders pointing at it.
+type User struct {
+	ID                 int
+	Name               string
+	Email              string
+	PasswordHash       string
+	Role               string
+	EmailVerifiedAt    *time.Time
+	VerificationSentAt *time.Time
+	DeletedAt          *time.Time
+}
+
+// RefreshToken is a stored refresh token, only its hash is kept.
+// Tokens rotated from the same login share a family.
+type RefreshToken struct {
+	ID       int
+	UserID   int
+	FamilyID string
+	Hash     string
+	Spent    bool // used or revoked
+	Expired  bool
+}
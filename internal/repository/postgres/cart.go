@@ -0,0 +1,97 @@
+package postgres
+
+import (
+	"context"
+
+	"w4/lc3/internal/repository"
+)
+
+// cart rows joined with the current product details
+const cartLinesQuery = `SELECT c.cart_id, c.product_id, COALESCE(p.name, ''), COALESCE(p.description, ''), p.price, c.quantity, p.stock, p.deleted_at IS NOT NULL, c.created_at
+	FROM carts c
+	JOIN products p ON p.product_id = c.product_id`
+
+const cartItemColumns = "cart_id, user_id, product_id, quantity, created_at"
+
+type cartRepo struct {
+	db DBTX
+}
+
+func (r cartRepo) Lines(ctx context.Context, userID int) ([]repository.CartLine, error) {
+	return r.lines(ctx, cartLinesQuery+" WHERE c.user_id = $1 ORDER BY c.cart_id", userID)
+}
+
+func (r cartRepo) LinesForUpdate(ctx context.Context, userID int) ([]repository.CartLine, error) {
+	return r.lines(ctx, cartLinesQuery+" WHERE c.user_id = $1 ORDER BY c.product_id FOR UPDATE OF c, p", userID)
+}
+
+func (r cartRepo) lines(ctx context.Context, query string, args ...any) ([]repository.CartLine, error) {
+	rows, err := r.db.Query(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	lines := []repository.CartLine{}
+	for rows.Next() {
+		var line repository.CartLine
+		if err := rows.Scan(&line.CartID, &line.ProductID, &line.Name, &line.Description, &line.UnitPrice, &line.Quantity, &line.Stock, &line.Deleted, &line.CreatedAt); err != nil {
+			return nil, err
+		}
+		lines = append(lines, line)
+	}
+	return lines, rows.Err()
+}
+
+func (r cartRepo) Line(ctx context.Context, userID, cartID int) (repository.CartLine, error) {
+	var line repository.CartLine
+	err := r.db.QueryRow(ctx, cartLinesQuery+" WHERE c.cart_id = $1 AND c.user_id = $2", cartID, userID).
+		Scan(&line.CartID, &line.ProductID, &line.Name, &line.Description, &line.UnitPrice, &line.Quantity, &line.Stock, &line.Deleted, &line.CreatedAt)
+	return line, notFound(err)
+}
+
+func (r cartRepo) Quantity(ctx context.Context, userID, productID int) (int, error) {
+	var quantity int
+	query := "SELECT COALESCE((SELECT quantity FROM carts WHERE user_id = $1 AND product_id = $2), 0)"
+	err := r.db.QueryRow(ctx, query, userID, productID).Scan(&quantity)
+	return quantity, err
+}
+
+func (r cartRepo) Add(ctx context.Context, userID, productID, quantity int) (repository.CartItem, error) {
+	// Add to the existing line when the product is already there
+	var item repository.CartItem
+	query := `INSERT INTO carts (user_id, product_id, quantity) VALUES ($1, $2, $3)
+		ON CONFLICT (user_id, product_id) DO UPDATE SET quantity = carts.quantity + EXCLUDED.quantity
+		RETURNING ` + cartItemColumns
+	err := r.db.QueryRow(ctx, query, userID, productID, quantity).
+		Scan(&item.CartID, &item.UserID, &item.ProductID, &item.Quantity, &item.CreatedAt)
+	return item, err
+}
+
+func (r cartRepo) SetQuantity(ctx context.Context, userID, cartID, quantity int) (repository.CartItem, error) {
+	var item repository.CartItem
+	query := `UPDATE carts SET quantity = $1 WHERE cart_id = $2 AND user_id = $3
+		RETURNING ` + cartItemColumns
+	err := r.db.QueryRow(ctx, query, quantity, cartID, userID).
+		Scan(&item.CartID, &item.UserID, &item.ProductID, &item.Quantity, &item.CreatedAt)
+	return item, notFound(err)
+}
+
+func (r cartRepo) Remove(ctx context.Context, userID, cartID int) error {
+	result, err := r.db.Exec(ctx, "DELETE FROM carts WHERE cart_id = $1 AND user_id = $2", cartID, userID)
+	if err != nil {
+		return err
+	}
+	if result.RowsAffected() == 0 {
+		return repository.ErrNotFound
+	}
+	return nil
+}
+
+func (r cartRepo) Clear(ctx context.Context, userID int) (int, error) {
+	result, err := r.db.Exec(ctx, "DELETE FROM carts WHERE user_id = $1", userID)
+	if err != nil {
+		return 0, err
+	}
+	return int(result.RowsAffected()), nil
+}
@@ -0,0 +1,37 @@
+package postgres
+
+import (
+	"context"
+
+	"w4/lc3/internal/repository"
+)
+
+type categoryRepo struct {
+	db DBTX
+}
+
+func (r categoryRepo) List(ctx context.Context) ([]*repository.Category, error) {
+	query := "SELECT category_id, name, slug, parent_id FROM categories ORDER BY name, category_id"
+	rows, err := r.db.Query(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var categories []*repository.Category
+	for rows.Next() {
+		category := &repository.Category{Children: []*repository.Category{}}
+		if err := rows.Scan(&category.CategoryID, &category.Name, &category.Slug, &category.ParentID); err != nil {
+			return nil, err
+		}
+		categories = append(categories, category)
+	}
+	return categories, rows.Err()
+}
+
+func (r categoryRepo) Get(ctx context.Context, categoryID int) (repository.Category, error) {
+	category := repository.Category{Children: []*repository.Category{}}
+	query := "SELECT category_id, name, slug, parent_id FROM categories WHERE category_id = $1"
+	err := r.db.QueryRow(ctx, query, categoryID).Scan(&category.CategoryID, &category.Name, &category.Slug, &category.ParentID)
+	return category, notFound(err)
+}
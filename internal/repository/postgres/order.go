@@ -0,0 +1,122 @@
+package postgres
+
+import (
+	"context"
+
+	"w4/lc3/internal/repository"
+)
+
+const orderColumns = "order_id, user_id, total_price, status, created_at"
+
+type orderRepo struct {
+	db DBTX
+}
+
+func (r orderRepo) List(ctx context.Context, userID int) ([]repository.Order, error) {
+	rows, err := r.db.Query(ctx, "SELECT "+orderColumns+" FROM orders WHERE user_id = $1 ORDER BY order_id", userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	orders := []repository.Order{}
+	for rows.Next() {
+		var order repository.Order
+		if err := rows.Scan(&order.OrderID, &order.UserID, &order.TotalPrice, &order.Status, &order.CreatedAt); err != nil {
+			return nil, err
+		}
+		orders = append(orders, order)
+	}
+	return orders, rows.Err()
+}
+
+func (r orderRepo) Get(ctx context.Context, orderID int) (repository.Order, error) {
+	return r.get(ctx, "SELECT "+orderColumns+" FROM orders WHERE order_id = $1", orderID)
+}
+
+func (r orderRepo) GetForUpdate(ctx context.Context, orderID int) (repository.Order, error) {
+	return r.get(ctx, "SELECT "+orderColumns+" FROM orders WHERE order_id = $1 FOR UPDATE", orderID)
+}
+
+func (r orderRepo) get(ctx context.Context, query string, orderID int) (repository.Order, error) {
+	var order repository.Order
+	err := r.db.QueryRow(ctx, query, orderID).Scan(&order.OrderID, &order.UserID, &order.TotalPrice, &order.Status, &order.CreatedAt)
+	return order, notFound(err)
+}
+
+func (r orderRepo) Items(ctx context.Context, orderID int) ([]repository.OrderItem, error) {
+	// The price is the snapshot taken at checkout
+	query := `SELECT oi.order_item_id, oi.product_id, p.name, oi.price, oi.quantity, oi.price * oi.quantity
+		FROM orderitems oi
+		JOIN products p ON p.product_id = oi.product_id
+		WHERE oi.order_id = $1
+		ORDER BY oi.order_item_id`
+	rows, err := r.db.Query(ctx, query, orderID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	items := []repository.OrderItem{}
+	for rows.Next() {
+		var item repository.OrderItem
+		if err := rows.Scan(&item.OrderItemID, &item.ProductID, &item.Name, &item.UnitPrice, &item.Quantity, &item.LineTotal); err != nil {
+			return nil, err
+		}
+		items = append(items, item)
+	}
+	return items, rows.Err()
+}
+
+func (r orderRepo) History(ctx context.Context, orderID int) ([]repository.StatusChange, error) {
+	query := `SELECT order_id, from_status, to_status, changed_by, changed_at
+		FROM orderstatushistory
+		WHERE order_id = $1
+		ORDER BY changed_at, history_id`
+	rows, err := r.db.Query(ctx, query, orderID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	history := []repository.StatusChange{}
+	for rows.Next() {
+		var change repository.StatusChange
+		if err := rows.Scan(&change.OrderID, &change.FromStatus, &change.ToStatus, &change.ChangedBy, &change.ChangedAt); err != nil {
+			return nil, err
+		}
+		history = append(history, change)
+	}
+	return history, rows.Err()
+}
+
+func (r orderRepo) Create(ctx context.Context, userID int, total float64) (repository.Order, error) {
+	var order repository.Order
+	query := "INSERT INTO orders (user_id, total_price, status) VALUES ($1, $2, $3) RETURNING " + orderColumns
+	err := r.db.QueryRow(ctx, query, userID, total, repository.StatusPending).
+		Scan(&order.OrderID, &order.UserID, &order.TotalPrice, &order.Status, &order.CreatedAt)
+	return order, err
+}
+
+func (r orderRepo) AddItem(ctx context.Context, orderID int, item repository.OrderItem) error {
+	query := "INSERT INTO orderitems (order_id, product_id, quantity, price) VALUES ($1, $2, $3, $4)"
+	_, err := r.db.Exec(ctx, query, orderID, item.ProductID, item.Quantity, item.UnitPrice)
+	return err
+}
+
+func (r orderRepo) SetStatus(ctx context.Context, orderID int, status string) error {
+	result, err := r.db.Exec(ctx, "UPDATE orders SET status = $1 WHERE order_id = $2", status, orderID)
+	if err != nil {
+		return err
+	}
+	if result.RowsAffected() == 0 {
+		return repository.ErrNotFound
+	}
+	return nil
+}
+
+func (r orderRepo) RecordStatusChange(ctx context.Context, change repository.StatusChange) error {
+	query := "INSERT INTO orderstatushistory (order_id, from_status, to_status, changed_by) VALUES ($1, $2, $3, $4)"
+	_, err := r.db.Exec(ctx, query, change.OrderID, change.FromStatus, change.ToStatus, change.ChangedBy)
+	return err
+}
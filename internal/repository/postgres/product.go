@@ -0,0 +1,262 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"w4/lc3/internal/repository"
+
+	"github.com/jackc/pgx/v5"
+)
+
+// options passed to ts_headline, matches are wrapped in <mark> tags
+const headlineOptions = "StartSel=<mark>, StopSel=</mark>, MaxFragments=2, MaxWords=25, MinWords=10"
+
+// productSort describes how a sort option orders the product list.
+// The product_id tie-breaker always runs in the same direction as the key,
+// which lets the keyset cursor compare (key, product_id) as a single row value.
+type productSort struct {
+	key  string // SQL expression of the sort key
+	cast string // type the cursor value is cast to when compared with key
+	desc bool
+}
+
+var productSorts = map[string]productSort{
+	"":       {},
+	"price":  {key: "price", cast: "numeric"},
+	"-price": {key: "price", cast: "numeric", desc: true},
+	"name":   {key: "COALESCE(name, '')", cast: "text"},
+	"newest": {key: "created_at", cast: "timestamp", desc: true},
+}
+
+const productColumns = "product_id, name, description, price, stock"
+
+type productRepo struct {
+	db DBTX
+}
+
+// queryBuilder collects WHERE conditions and their positional arguments
+type queryBuilder struct {
+	where []string
+	args  []interface{}
+}
+
+// arg registers a value and returns its placeholder
+func (q *queryBuilder) arg(v interface{}) string {
+	q.args = append(q.args, v)
+	return "$" + strconv.Itoa(len(q.args))
+}
+
+func (q *queryBuilder) whereClause() string {
+	return " WHERE " + strings.Join(q.where, " AND ")
+}
+
+// applyFilters adds the price, name and category conditions of the filter
+func applyFilters(f repository.ProductFilter, q *queryBuilder) {
+	if f.MinPrice != nil {
+		q.where = append(q.where, "price >= "+q.arg(*f.MinPrice))
+	}
+	if f.MaxPrice != nil {
+		q.where = append(q.where, "price <= "+q.arg(*f.MaxPrice))
+	}
+	if f.Name != "" {
+		q.where = append(q.where, "name ILIKE "+q.arg("%"+escapeLike(f.Name)+"%"))
+	}
+	if f.CategoryID != nil {
+		// UNION drops categories already visited, a cycle in parent_id ends the recursion instead of looping
+		q.where = append(q.where, `product_id IN (
+			WITH RECURSIVE tree AS (
+				SELECT category_id FROM categories WHERE category_id = `+q.arg(*f.CategoryID)+`
+				UNION
+				SELECT c.category_id FROM categories c JOIN tree t ON c.parent_id = t.category_id
+			)
+			SELECT pc.product_id FROM productcategories pc JOIN tree USING (category_id))`)
+	}
+}
+
+// escapeLike escapes the wildcard characters of a LIKE pattern
+func escapeLike(s string) string {
+	return strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`).Replace(s)
+}
+
+// List returns one page of non-deleted products matching the filter
+func (r productRepo) List(ctx context.Context, f repository.ProductFilter) (repository.ProductList, error) {
+	page := repository.ProductList{Products: []repository.Product{}}
+	sort, ok := productSorts[f.Sort]
+	if !ok {
+		return page, fmt.Errorf("unknown sort %q", f.Sort)
+	}
+
+	q := &queryBuilder{where: []string{"deleted_at IS NULL"}}
+	applyFilters(f, q)
+
+	// Total number of matches, ignoring the page window
+	err := r.db.QueryRow(ctx, "SELECT COUNT(*) FROM products"+q.whereClause(), q.args...).Scan(&page.Total)
+	if err != nil {
+		return page, err
+	}
+
+	dir, cmp := "ASC", ">"
+	if sort.desc {
+		dir, cmp = "DESC", "<"
+	}
+
+	keyExpr := "''"
+	orderBy := "product_id " + dir
+	if sort.key != "" {
+		keyExpr = sort.key + "::text"
+		orderBy = sort.key + " " + dir + ", " + orderBy
+	}
+
+	// Keyset paging continues strictly after the row encoded in the cursor
+	if f.After != "" {
+		cur, err := repository.DecodeCursorFor(f.After, f.Sort)
+		if err != nil {
+			return page, err
+		}
+		if sort.key != "" {
+			q.where = append(q.where, fmt.Sprintf("(%s, product_id) %s (%s::%s, %s)", sort.key, cmp, q.arg(cur.Key), sort.cast, q.arg(cur.ID)))
+		} else {
+			q.where = append(q.where, "product_id "+cmp+" "+q.arg(cur.ID))
+		}
+	}
+
+	// Fetch one extra row to know whether there is a next page
+	query := "SELECT " + productColumns + ", " + keyExpr + " FROM products" + q.whereClause() +
+		" ORDER BY " + orderBy + " LIMIT " + q.arg(f.Limit+1)
+	if f.After == "" {
+		query += " OFFSET " + q.arg((f.Page-1)*f.Limit)
+	}
+
+	rows, err := r.db.Query(ctx, query, q.args...)
+	if err != nil {
+		return page, err
+	}
+	defer rows.Close()
+
+	var lastKey string
+	for rows.Next() {
+		var product repository.Product
+		var key string
+		if err := rows.Scan(&product.ProductID, &product.Name, &product.Description, &product.Price, &product.Stock, &key); err != nil {
+			return page, err
+		}
+		if len(page.Products) == f.Limit {
+			// the extra row only tells us there is more, the cursor points at the last returned row
+			last := page.Products[len(page.Products)-1]
+			page.NextCursor = repository.EncodeCursor(repository.Cursor{Sort: f.Sort, Key: lastKey, ID: last.ProductID})
+			break
+		}
+		lastKey = key
+		page.Products = append(page.Products, product)
+	}
+
+	return page, rows.Err()
+}
+
+// Search ranks the products matching a websearch query and highlights the matched terms
+func (r productRepo) Search(ctx context.Context, query string, f repository.ProductFilter) (repository.SearchList, error) {
+	result := repository.SearchList{Results: []repository.SearchResult{}}
+
+	qb := &queryBuilder{where: []string{"deleted_at IS NULL"}}
+	tsquery := "websearch_to_tsquery('english', " + qb.arg(query) + ")"
+	qb.where = append(qb.where, "search_vector @@ "+tsquery)
+	applyFilters(f, qb)
+
+	// Total number of matches
+	err := r.db.QueryRow(ctx, "SELECT COUNT(*) FROM products"+qb.whereClause(), qb.args...).Scan(&result.Total)
+	if err != nil {
+		return result, err
+	}
+
+	options := qb.arg(headlineOptions)
+	sql := `SELECT ` + productColumns + `,
+			ts_rank(search_vector, ` + tsquery + `) AS score,
+			ts_headline('english', COALESCE(name, ''), ` + tsquery + `, ` + options + `),
+			ts_headline('english', COALESCE(description, ''), ` + tsquery + `, ` + options + `)
+		FROM products` + qb.whereClause() + `
+		ORDER BY score DESC, product_id
+		LIMIT ` + qb.arg(f.Limit) + ` OFFSET ` + qb.arg((f.Page-1)*f.Limit)
+
+	rows, err := r.db.Query(ctx, sql, qb.args...)
+	if err != nil {
+		return result, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var res repository.SearchResult
+		var score float32
+		if err := rows.Scan(&res.ProductID, &res.Name, &res.Description, &res.Price, &res.Stock, &score, &res.Highlights.Name, &res.Highlights.Description); err != nil {
+			return result, err
+		}
+		res.Score = float64(score)
+		result.Results = append(result.Results, res)
+	}
+	return result, rows.Err()
+}
+
+func (r productRepo) Get(ctx context.Context, productID int) (repository.Product, error) {
+	var p repository.Product
+	query := "SELECT " + productColumns + " FROM products WHERE product_id = $1 AND deleted_at IS NULL"
+	err := r.db.QueryRow(ctx, query, productID).Scan(&p.ProductID, &p.Name, &p.Description, &p.Price, &p.Stock)
+	return p, notFound(err)
+}
+
+func (r productRepo) Create(ctx context.Context, p repository.Product) (repository.Product, error) {
+	var created repository.Product
+	query := `INSERT INTO products (name, description, price, stock) VALUES ($1, $2, $3, $4)
+		RETURNING ` + productColumns
+	err := r.db.QueryRow(ctx, query, p.Name, p.Description, p.Price, p.Stock).
+		Scan(&created.ProductID, &created.Name, &created.Description, &created.Price, &created.Stock)
+	return created, err
+}
+
+func (r productRepo) Update(ctx context.Context, p repository.Product) (repository.Product, error) {
+	var updated repository.Product
+	query := `UPDATE products SET name = $1, description = $2, price = $3
+		WHERE product_id = $4 AND deleted_at IS NULL
+		RETURNING ` + productColumns
+	err := r.db.QueryRow(ctx, query, p.Name, p.Description, p.Price, p.ProductID).
+		Scan(&updated.ProductID, &updated.Name, &updated.Description, &updated.Price, &updated.Stock)
+	return updated, notFound(err)
+}
+
+func (r productRepo) Delete(ctx context.Context, productID int) error {
+	query := "UPDATE products SET deleted_at = CURRENT_TIMESTAMP WHERE product_id = $1 AND deleted_at IS NULL"
+	result, err := r.db.Exec(ctx, query, productID)
+	if err != nil {
+		return err
+	}
+	if result.RowsAffected() == 0 {
+		return repository.ErrNotFound
+	}
+	return nil
+}
+
+func (r productRepo) AdjustStock(ctx context.Context, productID, delta int) (int, error) {
+	// Conditional update so stock can never go negative, it also locks the product row
+	var stock int
+	query := "UPDATE products SET stock = stock + $1 WHERE product_id = $2 AND stock + $1 >= 0 RETURNING stock"
+	err := r.db.QueryRow(ctx, query, delta, productID).Scan(&stock)
+	if errors.Is(err, pgx.ErrNoRows) {
+		var exists bool
+		if err := r.db.QueryRow(ctx, "SELECT EXISTS (SELECT 1 FROM products WHERE product_id = $1)", productID).Scan(&exists); err != nil {
+			return 0, err
+		}
+		if !exists {
+			return 0, repository.ErrNotFound
+		}
+		return 0, repository.ErrInsufficientStock
+	}
+	return stock, err
+}
+
+func (r productRepo) RecordStockAdjustment(ctx context.Context, adj repository.StockAdjustment) error {
+	query := "INSERT INTO stockadjustments (product_id, delta, reason, note, adjusted_by) VALUES ($1, $2, $3, $4, $5)"
+	_, err := r.db.Exec(ctx, query, adj.ProductID, adj.Delta, adj.Reason, adj.Note, adj.AdjustedBy)
+	return err
+}
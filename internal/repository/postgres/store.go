@@ -0,0 +1,67 @@
+// Package postgres implements the repositories on PostgreSQL with pgx
+package postgres
+
+import (
+	"context"
+	"errors"
+
+	"w4/lc3/internal/repository"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// DBTX is satisfied by both the pool and a transaction
+type DBTX interface {
+	Exec(ctx context.Context, sql string, args ...any) (pgconn.CommandTag, error)
+	Query(ctx context.Context, sql string, args ...any) (pgx.Rows, error)
+	QueryRow(ctx context.Context, sql string, args ...any) pgx.Row
+}
+
+var _ repository.Store = (*Store)(nil)
+
+// Store implements repository.Store on a connection pool
+type Store struct {
+	pool *pgxpool.Pool // nil inside a transaction
+	db   DBTX
+}
+
+// New returns a store running its queries on pool
+func New(pool *pgxpool.Pool) *Store {
+	return &Store{pool: pool, db: pool}
+}
+
+func (s *Store) Products() repository.ProductRepository    { return productRepo{s.db} }
+func (s *Store) Categories() repository.CategoryRepository { return categoryRepo{s.db} }
+func (s *Store) Carts() repository.CartRepository          { return cartRepo{s.db} }
+func (s *Store) Orders() repository.OrderRepository        { return orderRepo{s.db} }
+func (s *Store) Users() repository.UserRepository          { return userRepo{s.db} }
+func (s *Store) Tokens() repository.TokenRepository        { return tokenRepo{s.db} }
+
+// WithTx runs fn in a transaction, or in the current one when s already belongs to a transaction
+func (s *Store) WithTx(ctx context.Context, fn func(tx repository.Store) error) error {
+	if s.pool == nil {
+		return fn(s)
+	}
+	return pgx.BeginFunc(ctx, s.pool, func(tx pgx.Tx) error {
+		return fn(&Store{db: tx})
+	})
+}
+
+// notFound turns pgx.ErrNoRows into repository.ErrNotFound
+func notFound(err error) error {
+	if errors.Is(err, pgx.ErrNoRows) {
+		return repository.ErrNotFound
+	}
+	return err
+}
+
+// emailTaken turns the unique violation on users.email into repository.ErrEmailTaken
+func emailTaken(err error) error {
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) && pgErr.Code == "23505" { // Unique violation (email already registered)
+		return repository.ErrEmailTaken
+	}
+	return err
+}
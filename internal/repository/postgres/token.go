@@ -0,0 +1,96 @@
+package postgres
+
+import (
+	"context"
+	"time"
+
+	"w4/lc3/internal/repository"
+)
+
+type tokenRepo struct {
+	db DBTX
+}
+
+func (r tokenRepo) CreateRefreshToken(ctx context.Context, t repository.RefreshToken, ttl time.Duration) error {
+	query := `INSERT INTO refreshtokens (user_id, family_id, token_hash, expires_at)
+		VALUES ($1, $2, $3, NOW() + $4 * INTERVAL '1 second')`
+	_, err := r.db.Exec(ctx, query, t.UserID, t.FamilyID, t.Hash, int(ttl.Seconds()))
+	return err
+}
+
+func (r tokenRepo) RefreshTokenForUpdate(ctx context.Context, hash string) (repository.RefreshToken, error) {
+	var t repository.RefreshToken
+	query := `SELECT token_id, user_id, family_id, token_hash,
+			used_at IS NOT NULL OR revoked_at IS NOT NULL, expires_at <= NOW()
+		FROM refreshtokens WHERE token_hash = $1 FOR UPDATE`
+	err := r.db.QueryRow(ctx, query, hash).Scan(&t.ID, &t.UserID, &t.FamilyID, &t.Hash, &t.Spent, &t.Expired)
+	return t, notFound(err)
+}
+
+func (r tokenRepo) MarkRefreshTokenUsed(ctx context.Context, tokenID int) error {
+	_, err := r.db.Exec(ctx, "UPDATE refreshtokens SET used_at = NOW() WHERE token_id = $1", tokenID)
+	return err
+}
+
+func (r tokenRepo) RevokeFamily(ctx context.Context, familyID string) error {
+	_, err := r.db.Exec(ctx, "UPDATE refreshtokens SET revoked_at = NOW() WHERE family_id = $1 AND revoked_at IS NULL", familyID)
+	return err
+}
+
+func (r tokenRepo) RevokeFamilyOf(ctx context.Context, userID int, hash string) error {
+	query := `UPDATE refreshtokens SET revoked_at = NOW()
+		WHERE revoked_at IS NULL AND user_id = $2
+		AND family_id = (SELECT family_id FROM refreshtokens WHERE token_hash = $1)`
+	_, err := r.db.Exec(ctx, query, hash, userID)
+	return err
+}
+
+func (r tokenRepo) RevokeUserSessions(ctx context.Context, userID int) error {
+	_, err := r.db.Exec(ctx, "UPDATE refreshtokens SET revoked_at = NOW() WHERE user_id = $1 AND revoked_at IS NULL", userID)
+	return err
+}
+
+func (r tokenRepo) RevokeAccessToken(ctx context.Context, jti string, expiresAt time.Time) error {
+	query := `INSERT INTO revokedtokens (jti, expires_at) VALUES ($1, to_timestamp($2))
+		ON CONFLICT (jti) DO NOTHING`
+	_, err := r.db.Exec(ctx, query, jti, expiresAt.Unix())
+	return err
+}
+
+func (r tokenRepo) IsAccessTokenRevoked(ctx context.Context, jti string) (bool, error) {
+	var revoked bool
+	err := r.db.QueryRow(ctx, "SELECT EXISTS (SELECT 1 FROM revokedtokens WHERE jti = $1)", jti).Scan(&revoked)
+	return revoked, err
+}
+
+func (r tokenRepo) PurgeRevokedAccessTokens(ctx context.Context) error {
+	_, err := r.db.Exec(ctx, "DELETE FROM revokedtokens WHERE expires_at < NOW()")
+	return err
+}
+
+func (r tokenRepo) CreateResetToken(ctx context.Context, userID int, hash string, ttl time.Duration) error {
+	query := `INSERT INTO passwordresettokens (user_id, token_hash, expires_at)
+		VALUES ($1, $2, NOW() + $3 * INTERVAL '1 second')`
+	_, err := r.db.Exec(ctx, query, userID, hash, int(ttl.Seconds()))
+	return err
+}
+
+func (r tokenRepo) SpendResetTokens(ctx context.Context, userID int) error {
+	_, err := r.db.Exec(ctx, "UPDATE passwordresettokens SET used_at = NOW() WHERE user_id = $1 AND used_at IS NULL", userID)
+	return err
+}
+
+func (r tokenRepo) UseResetToken(ctx context.Context, hash string) (int, error) {
+	// The row lock makes a concurrent second use find the token already used
+	var userID int
+	query := `UPDATE passwordresettokens SET used_at = NOW()
+		WHERE token_hash = $1 AND used_at IS NULL AND expires_at > NOW()
+		RETURNING user_id`
+	err := r.db.QueryRow(ctx, query, hash).Scan(&userID)
+	return userID, notFound(err)
+}
+
+func (r tokenRepo) DeleteResetTokens(ctx context.Context, userID int) error {
+	_, err := r.db.Exec(ctx, "DELETE FROM passwordresettokens WHERE user_id = $1", userID)
+	return err
+}
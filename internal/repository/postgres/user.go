@@ -0,0 +1,99 @@
+package postgres
+
+import (
+	"context"
+	"time"
+
+	"w4/lc3/internal/repository"
+
+	"github.com/jackc/pgx/v5"
+)
+
+const userColumns = "user_id, COALESCE(name, ''), email, password, role, email_verified_at, verification_sent_at, deleted_at"
+
+type userRepo struct {
+	db DBTX
+}
+
+func scanUser(row pgx.Row) (repository.User, error) {
+	var u repository.User
+	err := row.Scan(&u.ID, &u.Name, &u.Email, &u.PasswordHash, &u.Role, &u.EmailVerifiedAt, &u.VerificationSentAt, &u.DeletedAt)
+	return u, notFound(err)
+}
+
+func (r userRepo) Create(ctx context.Context, u repository.User) (repository.User, error) {
+	query := `INSERT INTO users (name, email, password, role, email_verified_at, verification_sent_at)
+		VALUES ($1, $2, $3, $4, $5, $6) RETURNING ` + userColumns
+	created, err := scanUser(r.db.QueryRow(ctx, query, u.Name, u.Email, u.PasswordHash, u.Role, u.EmailVerifiedAt, u.VerificationSentAt))
+	return created, emailTaken(err)
+}
+
+func (r userRepo) Get(ctx context.Context, userID int) (repository.User, error) {
+	return scanUser(r.db.QueryRow(ctx, "SELECT "+userColumns+" FROM users WHERE user_id = $1 AND deleted_at IS NULL", userID))
+}
+
+func (r userRepo) GetForUpdate(ctx context.Context, userID int) (repository.User, error) {
+	return scanUser(r.db.QueryRow(ctx, "SELECT "+userColumns+" FROM users WHERE user_id = $1 AND deleted_at IS NULL FOR UPDATE", userID))
+}
+
+func (r userRepo) GetByEmail(ctx context.Context, email string) (repository.User, error) {
+	return scanUser(r.db.QueryRow(ctx, "SELECT "+userColumns+" FROM users WHERE email = $1 AND deleted_at IS NULL", email))
+}
+
+func (r userRepo) Update(ctx context.Context, u repository.User) error {
+	query := `UPDATE users SET name = $2, email = $3, password = $4, role = $5,
+			email_verified_at = $6, verification_sent_at = $7, deleted_at = $8
+		WHERE user_id = $1`
+	result, err := r.db.Exec(ctx, query, u.ID, u.Name, u.Email, u.PasswordHash, u.Role, u.EmailVerifiedAt, u.VerificationSentAt, u.DeletedAt)
+	if err != nil {
+		return emailTaken(err)
+	}
+	if result.RowsAffected() == 0 {
+		return repository.ErrNotFound
+	}
+	return nil
+}
+
+func (r userRepo) MarkVerified(ctx context.Context, userID int, email string) error {
+	query := `UPDATE users SET email_verified_at = COALESCE(email_verified_at, NOW())
+		WHERE user_id = $1 AND email = $2`
+	result, err := r.db.Exec(ctx, query, userID, email)
+	if err != nil {
+		return err
+	}
+	if result.RowsAffected() == 0 {
+		return repository.ErrNotFound
+	}
+	return nil
+}
+
+func (r userRepo) RestartVerification(ctx context.Context, userID int) error {
+	query := "UPDATE users SET email_verified_at = NULL, verification_sent_at = NOW() WHERE user_id = $1"
+	result, err := r.db.Exec(ctx, query, userID)
+	if err != nil {
+		return err
+	}
+	if result.RowsAffected() == 0 {
+		return repository.ErrNotFound
+	}
+	return nil
+}
+
+func (r userRepo) ClaimVerificationSend(ctx context.Context, userID int, wait time.Duration) (repository.User, error) {
+	// The cooldown check and the update happen in one statement, so two requests can't both claim the slot
+	query := `UPDATE users SET verification_sent_at = NOW()
+		WHERE user_id = $1 AND deleted_at IS NULL AND email_verified_at IS NULL
+		AND (verification_sent_at IS NULL OR verification_sent_at <= NOW() - $2 * INTERVAL '1 second')
+		RETURNING ` + userColumns
+	return scanUser(r.db.QueryRow(ctx, query, userID, int(wait.Seconds())))
+}
+
+func (r userRepo) VerificationCooldown(ctx context.Context, userID int, wait time.Duration) (bool, time.Duration, error) {
+	var verified bool
+	var seconds float64
+	query := `SELECT email_verified_at IS NOT NULL,
+			COALESCE(EXTRACT(EPOCH FROM verification_sent_at + $2 * INTERVAL '1 second' - NOW()), 0)
+		FROM users WHERE user_id = $1 AND deleted_at IS NULL`
+	err := r.db.QueryRow(ctx, query, userID, int(wait.Seconds())).Scan(&verified, &seconds)
+	return verified, time.Duration(seconds * float64(time.Second)), notFound(err)
+}
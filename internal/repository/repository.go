@@ -0,0 +1,133 @@
+// Package repository defines how the API reads and writes its data.
+// Services depend on these interfaces only; the postgres package implements them on the database
+// and the memory package keeps everything in process, for tests and offline runs.
+package repository
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+var (
+	// ErrNotFound is returned when the row asked for does not exist, or is deleted
+	ErrNotFound = errors.New("not found")
+	// ErrEmailTaken is returned when another account already uses the email
+	ErrEmailTaken = errors.New("email already registered")
+	// ErrInsufficientStock is returned when a stock change would go below zero
+	ErrInsufficientStock = errors.New("insufficient stock")
+)
+
+// Store gives access to every repository and runs work atomically.
+// The repositories of the Store passed to fn share one transaction, which is committed when fn returns nil.
+type Store interface {
+	Products() ProductRepository
+	Categories() CategoryRepository
+	Carts() CartRepository
+	Orders() OrderRepository
+	Users() UserRepository
+	Tokens() TokenRepository
+	WithTx(ctx context.Context, fn func(tx Store) error) error
+}
+
+// ProductRepository stores the catalog. Deleted products are soft deleted and only AdjustStock still sees them.
+type ProductRepository interface {
+	List(ctx context.Context, f ProductFilter) (ProductList, error)
+	// Search matches query against name and description, f.After and f.Sort are ignored
+	Search(ctx context.Context, query string, f ProductFilter) (SearchList, error)
+	Get(ctx context.Context, productID int) (Product, error)
+	Create(ctx context.Context, p Product) (Product, error)
+	// Update saves the name, description and price of p
+	Update(ctx context.Context, p Product) (Product, error)
+	Delete(ctx context.Context, productID int) error
+	// AdjustStock adds delta to the stock and returns the new stock, ErrInsufficientStock when it would go negative
+	AdjustStock(ctx context.Context, productID, delta int) (int, error)
+	RecordStockAdjustment(ctx context.Context, adj StockAdjustment) error
+}
+
+// CategoryRepository reads the category tree
+type CategoryRepository interface {
+	// List returns every category ordered by name
+	List(ctx context.Context) ([]*Category, error)
+	Get(ctx context.Context, categoryID int) (Category, error)
+}
+
+// CartRepository stores the carts, one row per user and product
+type CartRepository interface {
+	Lines(ctx context.Context, userID int) ([]CartLine, error)
+	// LinesForUpdate is Lines ordered by product, locking the cart and its products until the transaction ends
+	LinesForUpdate(ctx context.Context, userID int) ([]CartLine, error)
+	Line(ctx context.Context, userID, cartID int) (CartLine, error)
+	// Quantity is how many of the product are in the cart, 0 when none
+	Quantity(ctx context.Context, userID, productID int) (int, error)
+	// Add puts quantity of the product in the cart, on top of what is already there
+	Add(ctx context.Context, userID, productID, quantity int) (CartItem, error)
+	SetQuantity(ctx context.Context, userID, cartID, quantity int) (CartItem, error)
+	Remove(ctx context.Context, userID, cartID int) error
+	// Clear empties the cart and returns how many rows were removed
+	Clear(ctx context.Context, userID int) (int, error)
+}
+
+// OrderRepository stores orders, their items and status history
+type OrderRepository interface {
+	List(ctx context.Context, userID int) ([]Order, error)
+	Get(ctx context.Context, orderID int) (Order, error)
+	// GetForUpdate is Get, locking the order until the transaction ends
+	GetForUpdate(ctx context.Context, orderID int) (Order, error)
+	Items(ctx context.Context, orderID int) ([]OrderItem, error)
+	// History returns the status changes, oldest first
+	History(ctx context.Context, orderID int) ([]StatusChange, error)
+	// Create inserts a pending order
+	Create(ctx context.Context, userID int, total float64) (Order, error)
+	AddItem(ctx context.Context, orderID int, item OrderItem) error
+	SetStatus(ctx context.Context, orderID int, status string) error
+	RecordStatusChange(ctx context.Context, change StatusChange) error
+}
+
+// UserRepository stores the accounts. Get, GetForUpdate and GetByEmail only return accounts that are not deleted.
+type UserRepository interface {
+	// Create inserts u and returns it with its id, ErrEmailTaken when the email is in use
+	Create(ctx context.Context, u User) (User, error)
+	Get(ctx context.Context, userID int) (User, error)
+	// GetForUpdate is Get, locking the account until the transaction ends
+	GetForUpdate(ctx context.Context, userID int) (User, error)
+	GetByEmail(ctx context.Context, email string) (User, error)
+	// Update saves every field of u, ErrEmailTaken when the email is in use
+	Update(ctx context.Context, u User) error
+	// MarkVerified sets the verification time of the user if the email still matches, keeping the first one
+	MarkVerified(ctx context.Context, userID int, email string) error
+	// RestartVerification marks the email unverified with a verification email sent now.
+	// The time comes from the store's clock, the one ClaimVerificationSend and VerificationCooldown compare with.
+	RestartVerification(ctx context.Context, userID int) error
+	// ClaimVerificationSend records a verification email as sent now, if the email is unverified
+	// and the last one is older than wait. It returns ErrNotFound when the claim fails.
+	ClaimVerificationSend(ctx context.Context, userID int, wait time.Duration) (User, error)
+	// VerificationCooldown tells whether the email is verified and how long until the next verification email may be sent
+	VerificationCooldown(ctx context.Context, userID int, wait time.Duration) (verified bool, remaining time.Duration, err error)
+}
+
+// TokenRepository stores the refresh tokens, the revoked access tokens and the password reset tokens.
+// Tokens are looked up by hash, the plain values are never stored.
+type TokenRepository interface {
+	// CreateRefreshToken stores t, expiring ttl from now
+	CreateRefreshToken(ctx context.Context, t RefreshToken, ttl time.Duration) error
+	// RefreshTokenForUpdate finds a refresh token by hash and locks it until the transaction ends
+	RefreshTokenForUpdate(ctx context.Context, hash string) (RefreshToken, error)
+	MarkRefreshTokenUsed(ctx context.Context, tokenID int) error
+	RevokeFamily(ctx context.Context, familyID string) error
+	// RevokeFamilyOf revokes the family of the token with the given hash, only if it belongs to the user
+	RevokeFamilyOf(ctx context.Context, userID int, hash string) error
+	RevokeUserSessions(ctx context.Context, userID int) error
+
+	RevokeAccessToken(ctx context.Context, jti string, expiresAt time.Time) error
+	IsAccessTokenRevoked(ctx context.Context, jti string) (bool, error)
+	// PurgeRevokedAccessTokens forgets revoked tokens that have expired anyway
+	PurgeRevokedAccessTokens(ctx context.Context) error
+
+	CreateResetToken(ctx context.Context, userID int, hash string, ttl time.Duration) error
+	// SpendResetTokens marks every unused reset token of the user as used
+	SpendResetTokens(ctx context.Context, userID int) error
+	// UseResetToken spends an unused, unexpired reset token and returns its user, ErrNotFound otherwise
+	UseResetToken(ctx context.Context, hash string) (int, error)
+	DeleteResetTokens(ctx context.Context, userID int) error
+}
@@ -0,0 +1,120 @@
+package service
+
+import (
+	"context"
+	"errors"
+
+	"w4/lc3/internal/apperror"
+	"w4/lc3/internal/pricing"
+	"w4/lc3/internal/repository"
+)
+
+// CartView is a cart with its lines priced and the totals checkout would charge
+type CartView struct {
+	Cart    []repository.CartLine `json:"cart"`
+	Summary pricing.Summary       `json:"summary"`
+}
+
+var errExceedsStock = apperror.Conflict("Requested quantity exceeds available stock").WithCode(apperror.CodeInsufficientStock)
+
+// CartService manages the cart of each user
+type CartService struct {
+	store repository.Store
+}
+
+// NewCartService returns a cart service on store
+func NewCartService(store repository.Store) *CartService {
+	return &CartService{store: store}
+}
+
+// View returns the cart of the user with line subtotals, availability and the totals
+func (s *CartService) View(ctx context.Context, userID int) (CartView, error) {
+	lines, err := s.store.Carts().Lines(ctx, userID)
+	if err != nil {
+		return CartView{}, apperror.Internal("Failed to retrieve cart data", err)
+	}
+
+	view := CartView{Cart: []repository.CartLine{}}
+	var priced []pricing.Line
+	for _, line := range lines {
+		p := pricing.Line{ProductID: line.ProductID, UnitPrice: line.UnitPrice, Quantity: line.Quantity}
+		line.LineSubtotal = pricing.LineTotal(p)
+		line.Available = !line.Deleted && line.Stock >= line.Quantity
+		view.Cart = append(view.Cart, line)
+		priced = append(priced, p)
+	}
+
+	// Totals come from the same calculator checkout uses
+	view.Summary = pricing.Summarize(priced)
+	return view, nil
+}
+
+// Add puts a product in the cart, adding to the quantity when it is already there
+func (s *CartService) Add(ctx context.Context, userID, productID, quantity int) (repository.CartItem, error) {
+	// Check the product exists and has enough stock for what is already in the cart plus this request
+	product, err := s.store.Products().Get(ctx, productID)
+	if errors.Is(err, repository.ErrNotFound) {
+		return repository.CartItem{}, apperror.NotFound("Product not found")
+	}
+	if err != nil {
+		return repository.CartItem{}, apperror.Internal("Failed to add to cart", err)
+	}
+	inCart, err := s.store.Carts().Quantity(ctx, userID, productID)
+	if err != nil {
+		return repository.CartItem{}, apperror.Internal("Failed to add to cart", err)
+	}
+	if inCart+quantity > product.Stock {
+		return repository.CartItem{}, errExceedsStock
+	}
+
+	item, err := s.store.Carts().Add(ctx, userID, productID, quantity)
+	if err != nil {
+		return item, apperror.Internal("Failed to add to cart", err)
+	}
+	return item, nil
+}
+
+// SetQuantity sets an absolute quantity for a cart item of the user
+func (s *CartService) SetQuantity(ctx context.Context, userID, cartID, quantity int) (repository.CartItem, error) {
+	// Check the new quantity against the product stock
+	line, err := s.store.Carts().Line(ctx, userID, cartID)
+	if errors.Is(err, repository.ErrNotFound) {
+		return repository.CartItem{}, apperror.NotFound("Cart item not found")
+	}
+	if err != nil {
+		return repository.CartItem{}, apperror.Internal("Failed to update item", err)
+	}
+	if quantity > line.Stock {
+		return repository.CartItem{}, errExceedsStock
+	}
+
+	item, err := s.store.Carts().SetQuantity(ctx, userID, cartID, quantity)
+	if errors.Is(err, repository.ErrNotFound) {
+		return item, apperror.NotFound("Cart item not found")
+	}
+	if err != nil {
+		return item, apperror.Internal("Failed to update item", err)
+	}
+	return item, nil
+}
+
+// Remove deletes one cart item of the user
+func (s *CartService) Remove(ctx context.Context, userID, cartID int) error {
+	err := s.store.Carts().Remove(ctx, userID, cartID)
+	if errors.Is(err, repository.ErrNotFound) {
+		return apperror.NotFound("Cart item not found")
+	}
+	if err != nil {
+		return apperror.Internal("Failed to delete item", err)
+	}
+	return nil
+}
+
+// Clear empties the cart of the user and returns how many items were removed
+func (s *CartService) Clear(ctx context.Context, userID int) (int, error) {
+	removed, err := s.store.Carts().Clear(ctx, userID)
+	if err != nil {
+		return 0, apperror.Internal("Failed to empty cart", err)
+	}
+	return removed, nil
+}
@@ -0,0 +1,67 @@
+package service
+
+import (
+	"context"
+	"errors"
+
+	"w4/lc3/internal/apperror"
+	"w4/lc3/internal/repository"
+)
+
+// CategoryService reads the category tree and the products filed under it
+type CategoryService struct {
+	store repository.Store
+}
+
+// NewCategoryService returns a category service on store
+func NewCategoryService(store repository.Store) *CategoryService {
+	return &CategoryService{store: store}
+}
+
+// Tree returns every category nested under its parent
+func (s *CategoryService) Tree(ctx context.Context) ([]*repository.Category, error) {
+	categories, err := s.store.Categories().List(ctx)
+	if err != nil {
+		return nil, apperror.Internal("Failed to retrieve categories", err)
+	}
+	return BuildTree(categories), nil
+}
+
+// Products returns one page of the products in a category or any of its subcategories
+func (s *CategoryService) Products(ctx context.Context, categoryID int, f repository.ProductFilter) (repository.ProductList, error) {
+	// Make sure the category exists so an unknown id is a 404 rather than an empty page
+	_, err := s.store.Categories().Get(ctx, categoryID)
+	if errors.Is(err, repository.ErrNotFound) {
+		return repository.ProductList{}, apperror.NotFound("Category not found")
+	}
+	if err != nil {
+		return repository.ProductList{}, apperror.Internal("Failed to retrieve category", err)
+	}
+
+	f.CategoryID = &categoryID
+	page, err := s.store.Products().List(ctx, f)
+	if err != nil {
+		return page, apperror.Internal("Failed to retrieve products", err)
+	}
+	return page, nil
+}
+
+// BuildTree attaches every category to its parent and returns the roots, keeping the input order among siblings
+func BuildTree(categories []*repository.Category) []*repository.Category {
+	byID := make(map[int]*repository.Category, len(categories))
+	for _, category := range categories {
+		byID[category.CategoryID] = category
+	}
+
+	roots := []*repository.Category{}
+	for _, category := range categories {
+		if category.ParentID == nil {
+			roots = append(roots, category)
+			continue
+		}
+		if parent, ok := byID[*category.ParentID]; ok {
+			parent.Children = append(parent.Children, category)
+		}
+	}
+	return roots
+}
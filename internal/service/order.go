@@ -0,0 +1,219 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+
+	"w4/lc3/internal/apperror"
+	"w4/lc3/internal/pricing"
+	"w4/lc3/internal/repository"
+)
+
+// allowed transitions from each status, delivered and cancelled are final
+var orderTransitions = map[string][]string{
+	repository.StatusPending: {repository.StatusPaid, repository.StatusCancelled},
+	repository.StatusPaid:    {repository.StatusShipped, repository.StatusCancelled},
+	repository.StatusShipped: {repository.StatusDelivered},
+}
+
+// CanTransition reports whether an order may move from one status to another
+func CanTransition(from, to string) bool {
+	return containsStatus(orderTransitions[from], to)
+}
+
+func containsStatus(statuses []string, status string) bool {
+	for _, s := range statuses {
+		if s == status {
+			return true
+		}
+	}
+	return false
+}
+
+// OrderDetail is an order with its line items and status history
+type OrderDetail struct {
+	repository.Order
+	Items   []repository.OrderItem    `json:"items"`
+	History []repository.StatusChange `json:"history"`
+}
+
+// Receipt is what checkout charged for a new order
+type Receipt struct {
+	OrderID int
+	Summary pricing.Summary
+}
+
+// OrderService places orders and moves them through their statuses
+type OrderService struct {
+	store repository.Store
+}
+
+// NewOrderService returns an order service on store
+func NewOrderService(store repository.Store) *OrderService {
+	return &OrderService{store: store}
+}
+
+// List returns the orders of the user
+func (s *OrderService) List(ctx context.Context, userID int) ([]repository.Order, error) {
+	orders, err := s.store.Orders().List(ctx, userID)
+	if err != nil {
+		return nil, apperror.Internal("Failed to retrieve orders", err)
+	}
+	return orders, nil
+}
+
+// Detail returns an order of the user with its items and history, orders of other users look missing
+func (s *OrderService) Detail(ctx context.Context, userID, orderID int) (OrderDetail, error) {
+	var detail OrderDetail
+	order, err := s.store.Orders().Get(ctx, orderID)
+	if errors.Is(err, repository.ErrNotFound) || (err == nil && order.UserID != userID) {
+		return detail, apperror.NotFound("Order not found")
+	}
+	if err != nil {
+		return detail, apperror.Internal("Failed to retrieve order", err)
+	}
+	detail.Order = order
+
+	// Line items carry the price snapshot taken at checkout
+	detail.Items, err = s.store.Orders().Items(ctx, orderID)
+	if err != nil {
+		return detail, apperror.Internal("Failed to retrieve order items", err)
+	}
+	detail.History, err = s.store.Orders().History(ctx, orderID)
+	if err != nil {
+		return detail, apperror.Internal("Failed to retrieve order history", err)
+	}
+	return detail, nil
+}
+
+// Checkout turns the cart of the user into a pending order, reserving the stock and clearing the cart.
+// Everything runs in one transaction so a failure leaves the cart untouched.
+func (s *OrderService) Checkout(ctx context.Context, userID int) (Receipt, error) {
+	// Only verified accounts can check out
+	user, err := s.store.Users().Get(ctx, userID)
+	if errors.Is(err, repository.ErrNotFound) {
+		return Receipt{}, apperror.Unauthorized("Unauthorized")
+	}
+	if err != nil {
+		return Receipt{}, apperror.Internal("Failed to check user", err)
+	}
+	if user.EmailVerifiedAt == nil {
+		return Receipt{}, apperror.Forbidden("Verify your email before placing an order").WithCode(apperror.CodeEmailNotVerified)
+	}
+
+	var receipt Receipt
+	err = withTx(ctx, s.store, "Failed to commit order", func(tx repository.Store) error {
+		// Step 1: Fetch all cart items for the user together with the current product price
+		cart, err := tx.Carts().LinesForUpdate(ctx, userID)
+		if err != nil {
+			return apperror.Internal("Failed to fetch cart items", err)
+		}
+		if len(cart) == 0 {
+			return apperror.BadRequest("Cart is empty").WithCode(apperror.CodeCartEmpty)
+		}
+		// Products are always locked in id order, two checkouts sharing products can't deadlock
+		sort.Slice(cart, func(i, j int) bool { return cart[i].ProductID < cart[j].ProductID })
+
+		// Step 2: Calculate the totals with the same calculator the cart view uses
+		var lines []pricing.Line
+		for _, item := range cart {
+			if item.Deleted {
+				return apperror.Conflict(fmt.Sprintf("Product %d is no longer available", item.ProductID)).WithCode(apperror.CodeProductUnavailable)
+			}
+			lines = append(lines, pricing.Line{ProductID: item.ProductID, UnitPrice: item.UnitPrice, Quantity: item.Quantity})
+		}
+		receipt.Summary = pricing.Summarize(lines)
+
+		// Step 3: Create the order and record its initial status
+		order, err := tx.Orders().Create(ctx, userID, receipt.Summary.GrandTotal)
+		if err != nil {
+			return apperror.Internal("Failed to create order", err)
+		}
+		receipt.OrderID = order.OrderID
+		change := repository.StatusChange{OrderID: order.OrderID, ToStatus: repository.StatusPending, ChangedBy: &userID}
+		if err := tx.Orders().RecordStatusChange(ctx, change); err != nil {
+			return apperror.Internal("Failed to create order", err)
+		}
+
+		// Step 4: Reserve stock and snapshot every cart line into the order with the price paid
+		for _, item := range cart {
+			_, err := tx.Products().AdjustStock(ctx, item.ProductID, -item.Quantity)
+			if errors.Is(err, repository.ErrInsufficientStock) || errors.Is(err, repository.ErrNotFound) {
+				return apperror.Conflict(fmt.Sprintf("Insufficient stock for product %d", item.ProductID)).WithCode(apperror.CodeInsufficientStock)
+			}
+			if err != nil {
+				return apperror.Internal("Failed to reserve stock", err)
+			}
+
+			orderItem := repository.OrderItem{ProductID: item.ProductID, UnitPrice: item.UnitPrice, Quantity: item.Quantity}
+			if err := tx.Orders().AddItem(ctx, order.OrderID, orderItem); err != nil {
+				return apperror.Internal("Failed to create order items", err)
+			}
+		}
+
+		// Step 5: Clear the user's cart
+		if _, err := tx.Carts().Clear(ctx, userID); err != nil {
+			return apperror.Internal("Failed to clear cart", err)
+		}
+		return nil
+	})
+	return receipt, err
+}
+
+// Cancel cancels a pending order of the user
+func (s *OrderService) Cancel(ctx context.Context, userID, orderID int) error {
+	return s.transition(ctx, orderID, &userID, repository.StatusCancelled, userID, repository.StatusPending)
+}
+
+// UpdateStatus moves any order to a new status on behalf of an admin
+func (s *OrderService) UpdateStatus(ctx context.Context, actorID, orderID int, status string) error {
+	return s.transition(ctx, orderID, nil, status, actorID)
+}
+
+// transition moves an order to a new status and records the change in the history.
+// When ownerID is not nil the order must belong to that user, and allowedFrom, if given,
+// further restricts the statuses the change may start from.
+func (s *OrderService) transition(ctx context.Context, orderID int, ownerID *int, to string, actorID int, allowedFrom ...string) error {
+	return withTx(ctx, s.store, "Failed to update order status", func(tx repository.Store) error {
+		// Lock the order so concurrent changes are serialised
+		order, err := tx.Orders().GetForUpdate(ctx, orderID)
+		if errors.Is(err, repository.ErrNotFound) || (err == nil && ownerID != nil && order.UserID != *ownerID) {
+			return apperror.NotFound("Order not found")
+		}
+		if err != nil {
+			return apperror.Internal("Failed to update order status", err)
+		}
+
+		from := order.Status
+		if !CanTransition(from, to) || (len(allowedFrom) > 0 && !containsStatus(allowedFrom, from)) {
+			message := fmt.Sprintf("Cannot change order status from %s to %s", from, to)
+			return apperror.Conflict(message).WithCode(apperror.CodeInvalidTransition)
+		}
+
+		if err := tx.Orders().SetStatus(ctx, orderID, to); err != nil {
+			return apperror.Internal("Failed to update order status", err)
+		}
+
+		// Cancelled orders give their reserved stock back
+		if to == repository.StatusCancelled {
+			items, err := tx.Orders().Items(ctx, orderID)
+			if err != nil {
+				return apperror.Internal("Failed to restock cancelled order", err)
+			}
+			sort.Slice(items, func(i, j int) bool { return items[i].ProductID < items[j].ProductID })
+			for _, item := range items {
+				if _, err := tx.Products().AdjustStock(ctx, item.ProductID, item.Quantity); err != nil {
+					return apperror.Internal("Failed to restock cancelled order", err)
+				}
+			}
+		}
+
+		change := repository.StatusChange{OrderID: orderID, FromStatus: &from, ToStatus: to, ChangedBy: &actorID}
+		if err := tx.Orders().RecordStatusChange(ctx, change); err != nil {
+			return apperror.Internal("Failed to record status change", err)
+		}
+		return nil
+	})
+}
@@ -0,0 +1,115 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"net/url"
+	"os"
+	"time"
+
+	"w4/lc3/internal/apperror"
+	"w4/lc3/internal/mailer"
+	"w4/lc3/internal/repository"
+
+	"golang.org/x/crypto/bcrypt"
+)
+
+const resetTokenTTL = time.Hour
+
+var errInvalidResetToken = apperror.BadRequest("Invalid or expired reset token").WithCode(apperror.CodeInvalidResetToken)
+
+// emailLink is the page an email points to, the base URL comes from env and defaults to a local frontend
+func emailLink(env, fallback, token string) string {
+	base := os.Getenv(env)
+	if base == "" {
+		base = fallback
+	}
+	return base + "?token=" + url.QueryEscape(token)
+}
+
+// ForgotPassword emails a single-use reset link to the account with the given email.
+// An unknown email is not an error so accounts can't be enumerated.
+func (s *UserService) ForgotPassword(ctx context.Context, email string) error {
+	// Step 1: Look the user up
+	user, err := s.store.Users().GetByEmail(ctx, email)
+	if errors.Is(err, repository.ErrNotFound) {
+		return nil
+	}
+	if err != nil {
+		return apperror.Internal("Internal Server Error", err)
+	}
+
+	token, err := randomToken(32)
+	if err != nil {
+		return apperror.Internal("Failed to generate reset token", err)
+	}
+
+	// Step 2: Only the newest link works, earlier unused ones are spent
+	err = withTx(ctx, s.store, "Failed to commit transaction", func(tx repository.Store) error {
+		if err := tx.Tokens().SpendResetTokens(ctx, user.ID); err != nil {
+			return apperror.Internal("Failed to create reset token", err)
+		}
+		if err := tx.Tokens().CreateResetToken(ctx, user.ID, hashToken(token), resetTokenTTL); err != nil {
+			return apperror.Internal("Failed to create reset token", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	// Step 3: Email the link
+	err = s.mail.Send(ctx, mailer.Message{
+		To:      user.Email,
+		Subject: "Reset your password",
+		Body: fmt.Sprintf("Someone asked to reset the password of your account.\n\n"+
+			"Open this link within %d minutes to choose a new password:\n%s\n\n"+
+			"If it wasn't you, ignore this email, your password stays the same.",
+			int(resetTokenTTL.Minutes()), emailLink("PASSWORD_RESET_URL", "http://localhost:3000/reset-password", token)),
+	})
+	if err != nil {
+		// answered like an unknown email, a failure only for real accounts would give them away
+		log.Printf("sending reset email to user %d: %v", user.ID, err)
+	}
+	return nil
+}
+
+// ResetPassword sets a new password with a token from the reset email and ends every session of the account
+func (s *UserService) ResetPassword(ctx context.Context, token, password string) error {
+	hashPassword, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
+	if err != nil {
+		return apperror.Internal("Internal Server Error", err)
+	}
+
+	return withTx(ctx, s.store, "Failed to commit transaction", func(tx repository.Store) error {
+		// Step 1: Spend the token, a concurrent second use finds it already used
+		userID, err := tx.Tokens().UseResetToken(ctx, hashToken(token))
+		if errors.Is(err, repository.ErrNotFound) {
+			return errInvalidResetToken
+		}
+		if err != nil {
+			return apperror.Internal("Failed to check reset token", err)
+		}
+
+		// Step 2: Store the new password
+		user, err := tx.Users().GetForUpdate(ctx, userID)
+		if errors.Is(err, repository.ErrNotFound) {
+			return errInvalidResetToken
+		}
+		if err != nil {
+			return apperror.Internal("Failed to update password", err)
+		}
+		user.PasswordHash = string(hashPassword)
+		if err := tx.Users().Update(ctx, user); err != nil {
+			return apperror.Internal("Failed to update password", err)
+		}
+
+		// Step 3: Whoever knew the old password may hold a session, end them all
+		if err := tx.Tokens().RevokeUserSessions(ctx, userID); err != nil {
+			return apperror.Internal("Failed to revoke sessions", err)
+		}
+		return nil
+	})
+}
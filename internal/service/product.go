@@ -0,0 +1,150 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"strings"
+
+	"w4/lc3/internal/apperror"
+	"w4/lc3/internal/repository"
+)
+
+// ProductPatch holds the fields of a partial product update, nil fields are left unchanged
+type ProductPatch struct {
+	Name        *string
+	Description *string
+	Price       *float64
+}
+
+// ProductService reads and edits the catalog
+type ProductService struct {
+	store repository.Store
+}
+
+// NewProductService returns a product service on store
+func NewProductService(store repository.Store) *ProductService {
+	return &ProductService{store: store}
+}
+
+// List returns one page of products matching the filter
+func (s *ProductService) List(ctx context.Context, f repository.ProductFilter) (repository.ProductList, error) {
+	page, err := s.store.Products().List(ctx, f)
+	if err != nil {
+		return page, apperror.Internal("Failed to retrieve products", err)
+	}
+	return page, nil
+}
+
+// Search returns one page of the products matching a full-text query, most relevant first
+func (s *ProductService) Search(ctx context.Context, query string, f repository.ProductFilter) (repository.SearchList, error) {
+	result, err := s.store.Products().Search(ctx, query, f)
+	if err != nil {
+		return result, apperror.Internal("Failed to search products", err)
+	}
+	return result, nil
+}
+
+// Get returns a product that is not deleted
+func (s *ProductService) Get(ctx context.Context, productID int) (repository.Product, error) {
+	product, err := s.store.Products().Get(ctx, productID)
+	if errors.Is(err, repository.ErrNotFound) {
+		return product, apperror.NotFound("Product not found")
+	}
+	if err != nil {
+		return product, apperror.Internal("Failed to retrieve product", err)
+	}
+	return product, nil
+}
+
+// Create adds a product with its initial stock
+func (s *ProductService) Create(ctx context.Context, p repository.Product) (repository.Product, error) {
+	p.Name = strings.TrimSpace(p.Name)
+	product, err := s.store.Products().Create(ctx, p)
+	if err != nil {
+		return product, apperror.Internal("Failed to create product", err)
+	}
+	return product, nil
+}
+
+// Replace overwrites the name, description and price of a product, the stock only changes through AdjustStock
+func (s *ProductService) Replace(ctx context.Context, p repository.Product) (repository.Product, error) {
+	p.Name = strings.TrimSpace(p.Name)
+	return s.save(ctx, s.store, p)
+}
+
+// Patch changes the fields of a product that are set in patch
+func (s *ProductService) Patch(ctx context.Context, productID int, patch ProductPatch) (repository.Product, error) {
+	var product repository.Product
+	err := withTx(ctx, s.store, "Failed to update product", func(tx repository.Store) error {
+		// Load the current product and overlay the fields that were sent
+		current, err := tx.Products().Get(ctx, productID)
+		if errors.Is(err, repository.ErrNotFound) {
+			return apperror.NotFound("Product not found")
+		}
+		if err != nil {
+			return apperror.Internal("Failed to update product", err)
+		}
+
+		if patch.Name != nil {
+			current.Name = strings.TrimSpace(*patch.Name)
+		}
+		if patch.Description != nil {
+			current.Description = *patch.Description
+		}
+		if patch.Price != nil {
+			current.Price = *patch.Price
+		}
+
+		product, err = s.save(ctx, tx, current)
+		return err
+	})
+	return product, err
+}
+
+// save writes the editable fields of a product and returns it
+func (s *ProductService) save(ctx context.Context, store repository.Store, p repository.Product) (repository.Product, error) {
+	product, err := store.Products().Update(ctx, p)
+	if errors.Is(err, repository.ErrNotFound) {
+		return product, apperror.NotFound("Product not found")
+	}
+	if err != nil {
+		return product, apperror.Internal("Failed to update product", err)
+	}
+	return product, nil
+}
+
+// Delete soft deletes a product, past order items keep referencing it
+func (s *ProductService) Delete(ctx context.Context, productID int) error {
+	err := s.store.Products().Delete(ctx, productID)
+	if errors.Is(err, repository.ErrNotFound) {
+		return apperror.NotFound("Product not found")
+	}
+	if err != nil {
+		return apperror.Internal("Failed to delete product", err)
+	}
+	return nil
+}
+
+// AdjustStock changes the stock of a product and records who did it and why, returning the new stock
+func (s *ProductService) AdjustStock(ctx context.Context, adj repository.StockAdjustment) (int, error) {
+	var stock int
+	err := withTx(ctx, s.store, "Failed to adjust stock", func(tx repository.Store) error {
+		var err error
+		stock, err = tx.Products().AdjustStock(ctx, adj.ProductID, adj.Delta)
+		switch {
+		case errors.Is(err, repository.ErrNotFound):
+			return apperror.NotFound("Product not found")
+		case errors.Is(err, repository.ErrInsufficientStock):
+			return apperror.Conflict("Stock cannot go below zero")
+		case err != nil:
+			return apperror.Internal("Failed to adjust stock", err)
+		}
+
+		// Record the adjustment for auditing
+		if err := tx.Products().RecordStockAdjustment(ctx, adj); err != nil {
+			return apperror.Internal("Failed to record stock adjustment", err)
+		}
+		return nil
+	})
+	return stock, err
+}
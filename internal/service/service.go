@@ -0,0 +1,22 @@
+// Package service holds the business rules of the API on top of the repositories.
+// Services return *apperror.Error for every failure, so handlers can return their errors as is.
+package service
+
+import (
+	"context"
+	"errors"
+
+	"w4/lc3/internal/apperror"
+	"w4/lc3/internal/repository"
+)
+
+// withTx runs fn in a transaction of store. Errors fn returns are passed through,
+// failing to begin or commit becomes an internal error with the given message.
+func withTx(ctx context.Context, store repository.Store, message string, fn func(tx repository.Store) error) error {
+	err := store.WithTx(ctx, fn)
+	var appErr *apperror.Error
+	if err != nil && !errors.As(err, &appErr) {
+		return apperror.Internal(message, err)
+	}
+	return err
+}
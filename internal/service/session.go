@@ -0,0 +1,171 @@
+package service
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"log"
+	"net/http"
+	"time"
+
+	"w4/lc3/internal/apperror"
+	"w4/lc3/internal/auth"
+	"w4/lc3/internal/repository"
+
+	"github.com/golang-jwt/jwt/v4"
+)
+
+const (
+	accessTokenTTL  = 15 * time.Minute
+	refreshTokenTTL = 30 * 24 * time.Hour
+)
+
+// TokenPair is a short-lived access token plus the refresh token used to renew it
+type TokenPair struct {
+	Token        string `json:"token"`
+	RefreshToken string `json:"refresh_token"`
+	TokenType    string `json:"token_type"`
+	ExpiresIn    int    `json:"expires_in"` // access token lifetime in seconds
+}
+
+var errInvalidRefreshToken = apperror.New(http.StatusUnauthorized, apperror.CodeInvalidRefreshToken, "Invalid or expired refresh token")
+
+// randomToken returns n random bytes encoded as base64url
+func randomToken(n int) (string, error) {
+	b := make([]byte, n)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
+
+// hashToken is the form a refresh or reset token is stored and looked up in
+func hashToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
+// issueTokens signs a new access token and stores a new refresh token in the given family
+func (s *UserService) issueTokens(ctx context.Context, tokens repository.TokenRepository, userID int, role, familyID string) (TokenPair, error) {
+	jti, err := randomToken(16)
+	if err != nil {
+		return TokenPair{}, err
+	}
+	tokenString, err := s.keys.Sign(auth.Claims{
+		UserID: userID,
+		Role:   role,
+		RegisteredClaims: jwt.RegisteredClaims{
+			ID:        jti,
+			ExpiresAt: jwt.NewNumericDate(time.Now().Add(accessTokenTTL)),
+		},
+	})
+	if err != nil {
+		return TokenPair{}, err
+	}
+
+	refreshToken, err := randomToken(32)
+	if err != nil {
+		return TokenPair{}, err
+	}
+	stored := repository.RefreshToken{UserID: userID, FamilyID: familyID, Hash: hashToken(refreshToken)}
+	if err := tokens.CreateRefreshToken(ctx, stored, refreshTokenTTL); err != nil {
+		return TokenPair{}, err
+	}
+
+	return TokenPair{
+		Token:        tokenString,
+		RefreshToken: refreshToken,
+		TokenType:    "Bearer",
+		ExpiresIn:    int(accessTokenTTL.Seconds()),
+	}, nil
+}
+
+// Refresh exchanges a refresh token for a new token pair in the same family.
+// Each refresh token can be used once, reusing one revokes every token of its family.
+func (s *UserService) Refresh(ctx context.Context, refreshToken string) (TokenPair, error) {
+	var pair TokenPair
+	var reused bool
+	err := withTx(ctx, s.store, "Failed to commit transaction", func(tx repository.Store) error {
+		// Step 1: Lock the stored token so two concurrent refreshes can't both rotate it
+		stored, err := tx.Tokens().RefreshTokenForUpdate(ctx, hashToken(refreshToken))
+		if errors.Is(err, repository.ErrNotFound) {
+			return errInvalidRefreshToken
+		}
+		if err != nil {
+			return apperror.Internal("Failed to fetch refresh token", err)
+		}
+
+		// Step 2: A token that was already rotated is being replayed, assume it was stolen and end the whole session.
+		// The revocation has to be committed, so the error is returned once the transaction is done.
+		if stored.Spent {
+			if err := tx.Tokens().RevokeFamily(ctx, stored.FamilyID); err != nil {
+				return apperror.Internal("Failed to revoke refresh tokens", err)
+			}
+			reused = true
+			return nil
+		}
+		if stored.Expired {
+			return errInvalidRefreshToken
+		}
+		user, err := tx.Users().Get(ctx, stored.UserID)
+		if errors.Is(err, repository.ErrNotFound) {
+			return errInvalidRefreshToken
+		}
+		if err != nil {
+			return apperror.Internal("Failed to fetch refresh token", err)
+		}
+
+		// Step 3: Rotate, the old token is spent and a new one joins the same family
+		if err := tx.Tokens().MarkRefreshTokenUsed(ctx, stored.ID); err != nil {
+			return apperror.Internal("Failed to rotate refresh token", err)
+		}
+		pair, err = s.issueTokens(ctx, tx.Tokens(), stored.UserID, user.Role, stored.FamilyID)
+		if err != nil {
+			return apperror.Internal("Invalid Generate Token", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return TokenPair{}, err
+	}
+	if reused {
+		return TokenPair{}, errInvalidRefreshToken
+	}
+	return pair, nil
+}
+
+// Logout revokes the access token of the principal and, when given, the refresh token family it belongs to
+func (s *UserService) Logout(ctx context.Context, principal *auth.Principal, refreshToken string) error {
+	tokens := s.store.Tokens()
+
+	// Step 1: Deny-list the access token until it would have expired anyway
+	if err := tokens.RevokeAccessToken(ctx, principal.TokenID, principal.ExpiresAt); err != nil {
+		return apperror.Internal("Failed to revoke token", err)
+	}
+
+	// Step 2: End the refresh token family, only if it belongs to the caller
+	if refreshToken != "" {
+		if err := tokens.RevokeFamilyOf(ctx, principal.UserID, hashToken(refreshToken)); err != nil {
+			return apperror.Internal("Failed to revoke refresh tokens", err)
+		}
+	}
+
+	// Step 3: Expired entries no longer need to be remembered
+	if err := tokens.PurgeRevokedAccessTokens(ctx); err != nil {
+		log.Print(err)
+	}
+	return nil
+}
+
+// IsRevoked reports whether the access token with the given jti was revoked
+func (s *UserService) IsRevoked(ctx context.Context, jti string) (bool, error) {
+	return s.store.Tokens().IsAccessTokenRevoked(ctx, jti)
+}
+
+// JWKS returns the public keys verifying access tokens
+func (s *UserService) JWKS() auth.JWKSet {
+	return s.keys.JWKS()
+}
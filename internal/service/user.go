@@ -0,0 +1,323 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"strings"
+	"time"
+
+	"w4/lc3/internal/apperror"
+	"w4/lc3/internal/auth"
+	"w4/lc3/internal/mailer"
+	"w4/lc3/internal/repository"
+
+	"golang.org/x/crypto/bcrypt"
+)
+
+// Profile is the logged in user's view of their own account
+type Profile struct {
+	ID            int    `json:"id"`
+	Name          string `json:"name"`
+	Email         string `json:"email"`
+	Role          string `json:"role"`
+	EmailVerified bool   `json:"email_verified"`
+}
+
+func profileOf(u repository.User) Profile {
+	return Profile{ID: u.ID, Name: u.Name, Email: u.Email, Role: u.Role, EmailVerified: u.EmailVerifiedAt != nil}
+}
+
+var (
+	// same error for unknown email and wrong password so accounts can't be enumerated
+	errInvalidCredentials = apperror.New(http.StatusUnauthorized, apperror.CodeInvalidCredentials, "Invalid email or password")
+	errWrongPassword      = apperror.BadRequest("Current password is incorrect").WithCode(apperror.CodeInvalidCredentials)
+	errEmailTaken         = apperror.BadRequest("Email already registered").WithCode(apperror.CodeEmailTaken)
+)
+
+// UserService manages accounts, their sessions and the emails sent to them
+type UserService struct {
+	store repository.Store
+	keys  *auth.KeySet
+	mail  mailer.Mailer
+}
+
+// NewUserService returns a user service on store, signing tokens with keys and sending email through mail
+func NewUserService(store repository.Store, keys *auth.KeySet, mail mailer.Mailer) *UserService {
+	return &UserService{store: store, keys: keys, mail: mail}
+}
+
+// now is the time stored in the timestamp columns written from Go
+func now() *time.Time {
+	t := time.Now().UTC()
+	return &t
+}
+
+// Register creates an unverified customer account and emails it a verification link.
+// A failed email only means the user has to ask for a resend, so it is logged rather than returned.
+func (s *UserService) Register(ctx context.Context, name, email, password string) (repository.User, error) {
+	hashPassword, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
+	if err != nil {
+		return repository.User{}, apperror.Internal("Internal Server Error", err)
+	}
+
+	// The account starts with its verification email counted as sent, the resend cooldown applies at once
+	var user repository.User
+	err = withTx(ctx, s.store, "Internal Server Error", func(tx repository.Store) error {
+		var err error
+		user, err = tx.Users().Create(ctx, repository.User{
+			Name:         name,
+			Email:        email,
+			PasswordHash: string(hashPassword),
+			Role:         auth.RoleCustomer,
+		})
+		if errors.Is(err, repository.ErrEmailTaken) {
+			return errEmailTaken
+		}
+		if err != nil {
+			return apperror.Internal("Internal Server Error", err)
+		}
+		if err := tx.Users().RestartVerification(ctx, user.ID); err != nil {
+			return apperror.Internal("Internal Server Error", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return user, err
+	}
+
+	if err := s.sendVerificationEmail(ctx, user.ID, user.Email); err != nil {
+		log.Printf("sending verification email to user %d: %v", user.ID, err)
+	}
+	return user, nil
+}
+
+// Login checks the credentials and starts a new session
+func (s *UserService) Login(ctx context.Context, email, password string) (TokenPair, error) {
+	user, err := s.store.Users().GetByEmail(ctx, email)
+	if errors.Is(err, repository.ErrNotFound) {
+		return TokenPair{}, errInvalidCredentials
+	}
+	if err != nil {
+		return TokenPair{}, apperror.Internal("Internal Server Error", err)
+	}
+	if err := bcrypt.CompareHashAndPassword([]byte(user.PasswordHash), []byte(password)); err != nil {
+		return TokenPair{}, errInvalidCredentials
+	}
+
+	// every login starts a new refresh token family
+	familyID, err := randomToken(16)
+	if err != nil {
+		return TokenPair{}, apperror.Internal("Invalid Generate Token", err)
+	}
+	pair, err := s.issueTokens(ctx, s.store.Tokens(), user.ID, user.Role, familyID)
+	if err != nil {
+		return TokenPair{}, apperror.Internal("Invalid Generate Token", err)
+	}
+	return pair, nil
+}
+
+// Profile returns the account of an active user
+func (s *UserService) Profile(ctx context.Context, userID int) (Profile, error) {
+	user, err := s.store.Users().Get(ctx, userID)
+	if errors.Is(err, repository.ErrNotFound) {
+		return Profile{}, apperror.Unauthorized("Unauthorized")
+	}
+	if err != nil {
+		return Profile{}, apperror.Internal("Failed to retrieve profile", err)
+	}
+	return profileOf(user), nil
+}
+
+// UpdateProfile changes the name and/or email of the user, the fields left nil are kept.
+// A new email starts unverified and gets a verification link.
+func (s *UserService) UpdateProfile(ctx context.Context, userID int, name, email *string) (Profile, error) {
+	var user repository.User
+	var emailChanged bool
+	err := withTx(ctx, s.store, "Failed to update profile", func(tx repository.Store) error {
+		// Step 1: Lock the account and see whether the email really changes
+		var err error
+		user, err = tx.Users().GetForUpdate(ctx, userID)
+		if errors.Is(err, repository.ErrNotFound) {
+			return apperror.Unauthorized("Unauthorized")
+		}
+		if err != nil {
+			return apperror.Internal("Failed to retrieve profile", err)
+		}
+		emailChanged = email != nil && !strings.EqualFold(*email, user.Email)
+
+		// Step 2: Save, a new email resets the verification
+		if name != nil {
+			user.Name = *name
+		}
+		if email != nil {
+			user.Email = *email
+		}
+		err = tx.Users().Update(ctx, user)
+		if errors.Is(err, repository.ErrEmailTaken) {
+			return errEmailTaken
+		}
+		if err != nil {
+			return apperror.Internal("Failed to update profile", err)
+		}
+		if emailChanged {
+			user.EmailVerifiedAt = nil
+			if err := tx.Users().RestartVerification(ctx, userID); err != nil {
+				return apperror.Internal("Failed to update profile", err)
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return Profile{}, err
+	}
+
+	// Step 3: Ask the new address to confirm it, a failed email can be retried with the resend endpoint
+	if emailChanged {
+		if err := s.sendVerificationEmail(ctx, userID, user.Email); err != nil {
+			log.Printf("sending verification email to user %d: %v", userID, err)
+		}
+	}
+	return profileOf(user), nil
+}
+
+// ChangePassword replaces the password of the caller after checking the current one.
+// Every session is ended and a new token pair is returned for the caller's.
+func (s *UserService) ChangePassword(ctx context.Context, principal *auth.Principal, current, next string) (TokenPair, error) {
+	var pair TokenPair
+	err := withTx(ctx, s.store, "Failed to commit transaction", func(tx repository.Store) error {
+		// Step 1: Check the current password
+		user, err := checkPassword(ctx, tx, principal.UserID, current)
+		if err != nil {
+			return err
+		}
+
+		// Step 2: Store the new hash
+		hashPassword, err := bcrypt.GenerateFromPassword([]byte(next), bcrypt.DefaultCost)
+		if err != nil {
+			return apperror.Internal("Internal Server Error", err)
+		}
+		user.PasswordHash = string(hashPassword)
+		if err := tx.Users().Update(ctx, user); err != nil {
+			return apperror.Internal("Failed to update password", err)
+		}
+
+		// Step 3: End every session, then start a fresh one for the caller
+		if err := tx.Tokens().RevokeUserSessions(ctx, principal.UserID); err != nil {
+			return apperror.Internal("Failed to revoke sessions", err)
+		}
+		familyID, err := randomToken(16)
+		if err != nil {
+			return apperror.Internal("Invalid Generate Token", err)
+		}
+		pair, err = s.issueTokens(ctx, tx.Tokens(), principal.UserID, principal.Roles[0], familyID)
+		if err != nil {
+			return apperror.Internal("Invalid Generate Token", err)
+		}
+		if err := tx.Tokens().RevokeAccessToken(ctx, principal.TokenID, principal.ExpiresAt); err != nil {
+			return apperror.Internal("Failed to revoke token", err)
+		}
+		return nil
+	})
+	return pair, err
+}
+
+// DeleteAccount erases the personal data of the caller after checking the password, and ends all sessions.
+// The row is anonymised instead of deleted so orders keep pointing at it.
+func (s *UserService) DeleteAccount(ctx context.Context, principal *auth.Principal, password string) error {
+	userID := principal.UserID
+	return withTx(ctx, s.store, "Failed to commit transaction", func(tx repository.Store) error {
+		// Step 1: Check the password
+		user, err := checkPassword(ctx, tx, userID, password)
+		if err != nil {
+			return err
+		}
+
+		// Step 2: Anonymise the account.
+		// The placeholder email keeps the unique constraint and frees the real one, the empty hash never matches a password.
+		user.Name = "Deleted user"
+		user.Email = fmt.Sprintf("deleted-%d@deleted.invalid", userID)
+		user.PasswordHash = ""
+		user.EmailVerifiedAt = nil
+		user.VerificationSentAt = nil
+		user.DeletedAt = now()
+		if err := tx.Users().Update(ctx, user); err != nil {
+			return apperror.Internal("Failed to delete account", err)
+		}
+
+		// Step 3: Drop what only matters to a live account
+		if _, err := tx.Carts().Clear(ctx, userID); err != nil {
+			return apperror.Internal("Failed to delete account", err)
+		}
+		if err := tx.Tokens().DeleteResetTokens(ctx, userID); err != nil {
+			return apperror.Internal("Failed to delete account", err)
+		}
+
+		// Step 4: End every session, including this one
+		if err := tx.Tokens().RevokeUserSessions(ctx, userID); err != nil {
+			return apperror.Internal("Failed to revoke sessions", err)
+		}
+		if err := tx.Tokens().RevokeAccessToken(ctx, principal.TokenID, principal.ExpiresAt); err != nil {
+			return apperror.Internal("Failed to revoke token", err)
+		}
+		return nil
+	})
+}
+
+// checkPassword locks an active user and compares password with the stored hash
+func checkPassword(ctx context.Context, tx repository.Store, userID int, password string) (repository.User, error) {
+	user, err := tx.Users().GetForUpdate(ctx, userID)
+	if errors.Is(err, repository.ErrNotFound) {
+		return user, apperror.Unauthorized("Unauthorized")
+	}
+	if err != nil {
+		return user, apperror.Internal("Internal Server Error", err)
+	}
+	if bcrypt.CompareHashAndPassword([]byte(user.PasswordHash), []byte(password)) != nil {
+		return user, errWrongPassword
+	}
+	return user, nil
+}
+
+// CreateUser creates an already verified account, for operators creating users from the command line.
+// Errors are plain errors meant for a terminal.
+func (s *UserService) CreateUser(ctx context.Context, name, email, password, role string) (int, error) {
+	hashPassword, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
+	if err != nil {
+		return 0, err
+	}
+
+	user, err := s.store.Users().Create(ctx, repository.User{
+		Name:            name,
+		Email:           email,
+		PasswordHash:    string(hashPassword),
+		Role:            role,
+		EmailVerifiedAt: now(),
+	})
+	if errors.Is(err, repository.ErrEmailTaken) {
+		return 0, fmt.Errorf("email %s is already registered", email)
+	}
+	return user.ID, err
+}
+
+// SetPassword replaces the password of the account with the given email and ends all of its sessions
+func (s *UserService) SetPassword(ctx context.Context, email, password string) error {
+	hashPassword, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
+	if err != nil {
+		return err
+	}
+
+	return s.store.WithTx(ctx, func(tx repository.Store) error {
+		user, err := tx.Users().GetByEmail(ctx, email)
+		if err != nil {
+			return fmt.Errorf("no active user with email %s: %w", email, err)
+		}
+		user.PasswordHash = string(hashPassword)
+		if err := tx.Users().Update(ctx, user); err != nil {
+			return err
+		}
+		return tx.Tokens().RevokeUserSessions(ctx, user.ID)
+	})
+}
@@ -0,0 +1,115 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+
+	"w4/lc3/internal/apperror"
+	"w4/lc3/internal/mailer"
+	"w4/lc3/internal/repository"
+
+	"github.com/golang-jwt/jwt/v4"
+)
+
+const (
+	verifyTokenTTL     = 24 * time.Hour
+	verifyResendWait   = time.Minute
+	verifyTokenPurpose = "email_verification"
+)
+
+// verificationClaims is the payload of a verification link. It has no user_id claim,
+// so the JWT middleware never accepts it as an access token.
+type verificationClaims struct {
+	Email   string `json:"email"`
+	Purpose string `json:"purpose"`
+	jwt.RegisteredClaims
+}
+
+var errInvalidVerifyToken = apperror.BadRequest("Invalid or expired verification token").WithCode(apperror.CodeInvalidVerifyToken)
+
+// sendVerificationEmail signs a verification token for the user and emails the link to it.
+// The token is bound to the email, so changing the email invalidates links sent to the old one.
+func (s *UserService) sendVerificationEmail(ctx context.Context, userID int, email string) error {
+	token, err := s.keys.Sign(verificationClaims{
+		Email:   email,
+		Purpose: verifyTokenPurpose,
+		RegisteredClaims: jwt.RegisteredClaims{
+			Subject:   strconv.Itoa(userID),
+			ExpiresAt: jwt.NewNumericDate(time.Now().Add(verifyTokenTTL)),
+		},
+	})
+	if err != nil {
+		return err
+	}
+
+	return s.mail.Send(ctx, mailer.Message{
+		To:      email,
+		Subject: "Verify your email",
+		Body: fmt.Sprintf("Welcome! Please confirm this is your email address.\n\n"+
+			"Open this link within %d hours to verify it:\n%s",
+			int(verifyTokenTTL.Hours()), emailLink("EMAIL_VERIFY_URL", "http://localhost:3000/verify-email", token)),
+	})
+}
+
+// VerifyEmail marks the email of a verification token as verified. Verifying twice is not an error.
+func (s *UserService) VerifyEmail(ctx context.Context, token string) error {
+	// Step 1: Check the signature, expiry and purpose of the token
+	claims := &verificationClaims{}
+	parsed, err := s.keys.Parse(token, claims)
+	if err != nil || !parsed.Valid || claims.Purpose != verifyTokenPurpose {
+		return errInvalidVerifyToken
+	}
+	userID, err := strconv.Atoi(claims.Subject)
+	if err != nil {
+		return errInvalidVerifyToken
+	}
+
+	// Step 2: Mark the email verified, keeping the first verification time
+	err = s.store.Users().MarkVerified(ctx, userID, claims.Email)
+	if errors.Is(err, repository.ErrNotFound) {
+		return errInvalidVerifyToken
+	}
+	if err != nil {
+		return apperror.Internal("Failed to verify email", err)
+	}
+	return nil
+}
+
+// ResendVerification sends a new verification link to the email of the user, at most once per verifyResendWait.
+// When refused because of the cooldown it returns the seconds to wait along with the error.
+func (s *UserService) ResendVerification(ctx context.Context, userID int) (retryAfter int, err error) {
+	// Step 1: Claim the send slot, the cooldown check and the update happen atomically
+	user, err := s.store.Users().ClaimVerificationSend(ctx, userID, verifyResendWait)
+	if errors.Is(err, repository.ErrNotFound) {
+		return s.resendRefused(ctx, userID)
+	}
+	if err != nil {
+		return 0, apperror.Internal("Internal Server Error", err)
+	}
+
+	// Step 2: Send the link
+	if err := s.sendVerificationEmail(ctx, userID, user.Email); err != nil {
+		return 0, apperror.Internal("Failed to send verification email", err)
+	}
+	return 0, nil
+}
+
+// resendRefused explains why ResendVerification sent nothing: the email is verified or the cooldown is running
+func (s *UserService) resendRefused(ctx context.Context, userID int) (int, error) {
+	verified, remaining, err := s.store.Users().VerificationCooldown(ctx, userID, verifyResendWait)
+	if errors.Is(err, repository.ErrNotFound) {
+		return 0, apperror.Unauthorized("Unauthorized")
+	}
+	if err != nil {
+		return 0, apperror.Internal("Internal Server Error", err)
+	}
+
+	if verified {
+		return 0, apperror.Conflict("Email already verified").WithCode(apperror.CodeAlreadyVerified)
+	}
+	seconds := int(remaining.Seconds()) + 1
+	return seconds, apperror.TooManyRequests(fmt.Sprintf("Please wait %d seconds before requesting another email", seconds))
+}
@@ -0,0 +1,19 @@
+package handler
+
+import (
+	"net/http"
+
+	"github.com/labstack/echo/v4"
+)
+
+// @Summary JSON Web Key Set
+// @Description Public keys other services can use to verify access tokens, matched to a token by its kid header
+// @Tags Users
+// @Produce  json
+// @Success 200 {object} auth.JWKSet "Public signing keys"
+// @Router /.well-known/jwks.json [get]
+func (h *Handler) JWKS(c echo.Context) error {
+	// let verifiers cache the set for a while, rotation keeps the previous key published
+	c.Response().Header().Set("Cache-Control", "public, max-age=300")
+	return c.JSON(http.StatusOK, h.users.JWKS())
+}
@@ -0,0 +1,86 @@
+package handler
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	"w4/lc3/internal/apperror"
+
+	"github.com/labstack/echo/v4"
+)
+
+// ForgotPasswordRequest struct
+type ForgotPasswordRequest struct {
+	Email string `json:"email" validate:"required,email"`
+}
+
+// ResetPasswordRequest struct
+type ResetPasswordRequest struct {
+	Token    string `json:"token" validate:"required"`
+	Password string `json:"password" validate:"required,password"` // New password for the account
+}
+
+// MessageResponse struct
+type MessageResponse struct {
+	Message string `json:"message"`
+}
+
+// @Summary Request a password reset
+// @Description Email a single-use password reset link valid for one hour. The response is the same whether or not the email is registered.
+// @Tags Users
+// @Accept  json
+// @Produce  json
+// @Param request body ForgotPasswordRequest true "Email of the account"
+// @Success 202 {object} MessageResponse "Reset email sent if the account exists"
+// @Failure 400 {object} apperror.Error "Invalid request"
+// @Failure 500 {object} apperror.Error "Internal server error"
+// @Router /users/password/forgot [post]
+func (h *Handler) ForgotPassword(c echo.Context) error {
+	var req ForgotPasswordRequest
+	if err := c.Bind(&req); err != nil {
+		return apperror.BadRequest("Invalid Request")
+	}
+	if err := c.Validate(&req); err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithTimeout(c.Request().Context(), 10*time.Second)
+	defer cancel()
+
+	// An unknown email gets the same answer so accounts can't be enumerated
+	if err := h.users.ForgotPassword(ctx, req.Email); err != nil {
+		return err
+	}
+
+	return c.JSON(http.StatusAccepted, MessageResponse{Message: "If the email is registered, a password reset link has been sent"})
+}
+
+// @Summary Reset the password
+// @Description Set a new password with a token from the reset email. The token can be used once, and every refresh token session of the account is ended.
+// @Tags Users
+// @Accept  json
+// @Produce  json
+// @Param request body ResetPasswordRequest true "Reset token and new password"
+// @Success 200 {object} MessageResponse "Password updated"
+// @Failure 400 {object} apperror.Error "Invalid request, invalid or expired token"
+// @Failure 500 {object} apperror.Error "Internal server error"
+// @Router /users/password/reset [post]
+func (h *Handler) ResetPassword(c echo.Context) error {
+	var req ResetPasswordRequest
+	if err := c.Bind(&req); err != nil {
+		return apperror.BadRequest("Invalid Request")
+	}
+	if err := c.Validate(&req); err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithTimeout(c.Request().Context(), 5*time.Second)
+	defer cancel()
+
+	if err := h.users.ResetPassword(ctx, req.Token, req.Password); err != nil {
+		return err
+	}
+
+	return c.JSON(http.StatusOK, MessageResponse{Message: "Password has been reset, please log in again"})
+}
@@ -0,0 +1,170 @@
+package handler
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	"w4/lc3/internal/apperror"
+	"w4/lc3/internal/auth"
+	"w4/lc3/internal/service"
+
+	"github.com/labstack/echo/v4"
+)
+
+// ProfileResponse is the logged in user's view of their own account
+type ProfileResponse = service.Profile
+
+// UpdateProfileRequest struct, only the fields that are set are changed
+type UpdateProfileRequest struct {
+	Name  *string `json:"name" validate:"omitempty,name"`
+	Email *string `json:"email" validate:"omitempty,email,max=100"`
+}
+
+// ChangePasswordRequest struct
+type ChangePasswordRequest struct {
+	CurrentPassword string `json:"current_password" validate:"required"`
+	NewPassword     string `json:"new_password" validate:"required,password"`
+}
+
+// DeleteAccountRequest struct, the password is asked again before the account is erased
+type DeleteAccountRequest struct {
+	Password string `json:"password" validate:"required"`
+}
+
+// @Summary Get my profile
+// @Description Retrieve the account of the logged in user
+// @Tags Users
+// @Produce  json
+// @Success 200 {object} ProfileResponse "Profile"
+// @Failure 401 {object} apperror.Error "Unauthorized"
+// @Failure 500 {object} apperror.Error "Internal server error"
+// @Router /users/me [get]
+func (h *Handler) GetProfile(c echo.Context) error {
+	// Extract user ID from the authenticated principal
+	principal, err := auth.CurrentPrincipal(c)
+	if err != nil {
+		return err
+	}
+
+	profile, err := h.users.Profile(c.Request().Context(), principal.UserID)
+	if err != nil {
+		return err
+	}
+	return c.JSON(http.StatusOK, profile)
+}
+
+// @Summary Update my profile
+// @Description Change the name and/or email of the logged in user. A new email starts unverified and gets a verification link.
+// @Tags Users
+// @Accept  json
+// @Produce  json
+// @Param request body UpdateProfileRequest true "Fields to change"
+// @Success 200 {object} ProfileResponse "Updated profile"
+// @Failure 400 {object} apperror.Error "Invalid input or email already exists"
+// @Failure 401 {object} apperror.Error "Unauthorized"
+// @Failure 500 {object} apperror.Error "Internal server error"
+// @Router /users/me [patch]
+func (h *Handler) UpdateProfile(c echo.Context) error {
+	// Extract user ID from the authenticated principal
+	principal, err := auth.CurrentPrincipal(c)
+	if err != nil {
+		return err
+	}
+	userID := principal.UserID
+
+	var req UpdateProfileRequest
+	if err := c.Bind(&req); err != nil {
+		return apperror.BadRequest("Invalid Request")
+	}
+	if err := c.Validate(&req); err != nil {
+		return err
+	}
+	if req.Name == nil && req.Email == nil {
+		return apperror.BadRequest("Nothing to update")
+	}
+
+	ctx, cancel := context.WithTimeout(c.Request().Context(), 10*time.Second)
+	defer cancel()
+
+	// A new email starts unverified and is sent a verification link
+	profile, err := h.users.UpdateProfile(ctx, userID, req.Name, req.Email)
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(http.StatusOK, profile)
+}
+
+// @Summary Change my password
+// @Description Replace the password of the logged in user after checking the current one. Every other session is ended and a new token pair is returned for this one.
+// @Tags Users
+// @Accept  json
+// @Produce  json
+// @Param request body ChangePasswordRequest true "Current and new password"
+// @Success 200 {object} LoginResponse "Password changed, new tokens for this session"
+// @Failure 400 {object} apperror.Error "Invalid input or wrong current password"
+// @Failure 401 {object} apperror.Error "Unauthorized"
+// @Failure 500 {object} apperror.Error "Internal server error"
+// @Router /users/me/password [post]
+func (h *Handler) ChangePassword(c echo.Context) error {
+	// Extract user ID from the authenticated principal
+	principal, err := auth.CurrentPrincipal(c)
+	if err != nil {
+		return err
+	}
+
+	var req ChangePasswordRequest
+	if err := c.Bind(&req); err != nil {
+		return apperror.BadRequest("Invalid Request")
+	}
+	if err := c.Validate(&req); err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithTimeout(c.Request().Context(), 5*time.Second)
+	defer cancel()
+
+	resp, err := h.users.ChangePassword(ctx, principal, req.CurrentPassword, req.NewPassword)
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(http.StatusOK, resp)
+}
+
+// @Summary Delete my account
+// @Description Erase the personal data of the logged in user and end all sessions. Orders are kept, attached to the anonymised account, for accounting.
+// @Tags Users
+// @Accept  json
+// @Produce  json
+// @Param request body DeleteAccountRequest true "Current password"
+// @Success 204 "Account deleted"
+// @Failure 400 {object} apperror.Error "Invalid input or wrong password"
+// @Failure 401 {object} apperror.Error "Unauthorized"
+// @Failure 500 {object} apperror.Error "Internal server error"
+// @Router /users/me [delete]
+func (h *Handler) DeleteAccount(c echo.Context) error {
+	// Extract user ID from the authenticated principal
+	principal, err := auth.CurrentPrincipal(c)
+	if err != nil {
+		return err
+	}
+
+	var req DeleteAccountRequest
+	if err := c.Bind(&req); err != nil {
+		return apperror.BadRequest("Invalid Request")
+	}
+	if err := c.Validate(&req); err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithTimeout(c.Request().Context(), 5*time.Second)
+	defer cancel()
+
+	if err := h.users.DeleteAccount(ctx, principal, req.Password); err != nil {
+		return err
+	}
+
+	return c.NoContent(http.StatusNoContent)
+}
@@ -0,0 +1,86 @@
+package handler
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	"w4/lc3/internal/apperror"
+	"w4/lc3/internal/auth"
+
+	"github.com/labstack/echo/v4"
+)
+
+// RefreshRequest struct
+type RefreshRequest struct {
+	RefreshToken string `json:"refresh_token" validate:"required"`
+}
+
+// LogoutRequest struct, the refresh token is optional but should be sent so the session can't be renewed
+type LogoutRequest struct {
+	RefreshToken string `json:"refresh_token"`
+}
+
+// @Summary Refresh the access token
+// @Description Exchange a refresh token for a new access token and a new refresh token. Each refresh token can be used once; reusing one revokes every token of its login session.
+// @Tags Users
+// @Accept  json
+// @Produce  json
+// @Param request body RefreshRequest true "Refresh token"
+// @Success 200 {object} LoginResponse "New access and refresh token"
+// @Failure 400 {object} apperror.Error "Invalid request"
+// @Failure 401 {object} apperror.Error "Invalid, expired or reused refresh token"
+// @Failure 500 {object} apperror.Error "Internal server error"
+// @Router /users/refresh [post]
+func (h *Handler) Refresh(c echo.Context) error {
+	var req RefreshRequest
+	if err := c.Bind(&req); err != nil {
+		return apperror.BadRequest("Invalid Request")
+	}
+	if err := c.Validate(&req); err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithTimeout(c.Request().Context(), 5*time.Second)
+	defer cancel()
+
+	resp, err := h.users.Refresh(ctx, req.RefreshToken)
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(http.StatusOK, resp)
+}
+
+// @Summary Logout
+// @Description Revoke the access token of the request and, when given, the refresh token session it belongs to
+// @Tags Users
+// @Accept  json
+// @Produce  json
+// @Param request body LogoutRequest false "Refresh token of the session to end"
+// @Success 204 "Logged out"
+// @Failure 400 {object} apperror.Error "Invalid request"
+// @Failure 401 {object} apperror.Error "Unauthorized"
+// @Failure 500 {object} apperror.Error "Internal server error"
+// @Router /users/logout [post]
+func (h *Handler) Logout(c echo.Context) error {
+	var req LogoutRequest
+	if err := c.Bind(&req); err != nil {
+		return apperror.BadRequest("Invalid Request")
+	}
+
+	// Extract the caller of the access token checked by the JWT middleware
+	principal, err := auth.CurrentPrincipal(c)
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithTimeout(c.Request().Context(), 5*time.Second)
+	defer cancel()
+
+	if err := h.users.Logout(ctx, principal, req.RefreshToken); err != nil {
+		return err
+	}
+
+	return c.NoContent(http.StatusNoContent)
+}
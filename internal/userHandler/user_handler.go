@@ -1,42 +1,31 @@
 package handler
 
 import (
-	"fmt"
-	"net/http"
-	config "w4/lc3/config/database"
-	
 	"context"
+	"net/http"
 	"time"
 
-	"github.com/golang-jwt/jwt/v4"
+	"w4/lc3/internal/apperror"
+	"w4/lc3/internal/service"
+
 	"github.com/labstack/echo/v4"
-	"golang.org/x/crypto/bcrypt"
-	"github.com/jackc/pgconn"
 )
 
-// -- Create Users table
-// CREATE TABLE Users (
-//     user_id SERIAL PRIMARY KEY,
-//     name VARCHAR(100),
-//     email VARCHAR(100) UNIQUE NOT NULL,
-//     password VARCHAR(255) NOT NULL,
-//     jwt_token VARCHAR(255)
-// );
-
-// Users struct
-type Users struct {
-	ID  int `json:"id"`
-	Name string `json:"name"`
-	Email string `json:"email"`
-	Password string `json:"password"`
-	JwtToken string `json:"jwt_token"`
+// Handler serves the account, session and profile endpoints
+type Handler struct {
+	users *service.UserService
+}
+
+// New returns the user handlers backed by users
+func New(users *service.UserService) *Handler {
+	return &Handler{users: users}
 }
 
 // RegisterRequest struct
 type RegisterRequest struct {
-	Name string `json:"name" validate:"required,name"` 				// Name of the user
-	Email    string `json:"email" validate:"required,email"`        // Email address
-	Password string `json:"password" validate:"required,password"`  // Password for the account
+	Name     string `json:"name" validate:"required,name"`           // Name of the user
+	Email    string `json:"email" validate:"required,email,max=100"` // Email address
+	Password string `json:"password" validate:"required,password"`   // Password for the account
 }
 
 // login request struct
@@ -45,60 +34,54 @@ type LoginRequest struct {
 	Password string `json:"password" validate:"required"`
 }
 
-// login response: token
-type LoginResponse struct {
-	Token string `json:"token"`
+// UserResponse is the public view of a user
+type UserResponse struct {
+	ID    int    `json:"id"`
+	Name  string `json:"name"`
+	Email string `json:"email"`
+}
+
+// RegisterResponse struct
+type RegisterResponse struct {
+	Message string       `json:"message"`
+	User    UserResponse `json:"user"`
 }
 
-var jwtSecret = []byte("12345")
+// login response: short-lived access token plus the refresh token used to renew it
+type LoginResponse = service.TokenPair
 
 // @Summary Register a new user
-// @Description Create a new user account by providing name, email, and password
+// @Description Create a new user account by providing name, email, and password. A verification link is emailed to the new, unverified account.
 // @Tags Users
 // @Accept  json
 // @Produce  json
 // @Param request body RegisterRequest true "User registration data"
-// @Success 201 {object} map[string]interface{} "User registered successfully"
-// @Failure 400 {object} map[string]string "Invalid input or email already exists"
-// @Failure 500 {object} map[string]string "Internal server error"
+// @Success 201 {object} RegisterResponse "User registered successfully"
+// @Failure 400 {object} apperror.Error "Invalid input or email already exists"
+// @Failure 500 {object} apperror.Error "Internal server error"
 // @Router /users/register [post]
-func Register(c echo.Context) error {
-    var req RegisterRequest
-    if err := c.Bind(&req); err != nil {
-        return c.JSON(http.StatusBadRequest, map[string]string{"message": "Invalid Request"})
-    }
-
-	// hash the password
-    hashPassword, err := bcrypt.GenerateFromPassword([]byte(req.Password), bcrypt.DefaultCost)
-    if err != nil {
-        return c.JSON(http.StatusInternalServerError, map[string]string{"message": "Internal Server Error"})
-    }
-
-    // queries to insert to both users and customers db
-	users_query := "INSERT INTO users (name, email, password) VALUES ($1, $2, $3) RETURNING user_id"
-	
-	var userID int
-	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+func (h *Handler) Register(c echo.Context) error {
+	var req RegisterRequest
+	if err := c.Bind(&req); err != nil {
+		return apperror.BadRequest("Invalid Request")
+	}
+	if err := c.Validate(&req); err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithTimeout(c.Request().Context(), 5*time.Second)
 	defer cancel()
 
-	// query row 1: insert to users 
-	err = config.Pool.QueryRow(ctx, users_query, req.Name, req.Email, string(hashPassword)).Scan(&userID)
+	// new accounts start unverified, a failed verification email only means the user has to ask for a resend
+	user, err := h.users.Register(ctx, req.Name, req.Email, req.Password)
 	if err != nil {
-		fmt.Println("Error inserting into users table:", err)
-
-		if pgErr, ok := err.(*pgconn.PgError); ok {
-			if pgErr.Code == "23505" { // Unique violation (email already registered)
-				return c.JSON(http.StatusBadRequest, map[string]string{"message": "Email already registered"})
-			}
-		}
-		return c.JSON(http.StatusInternalServerError, map[string]string{"message": "Internal Server Error"})
+		return err
 	}
 
-    return c.JSON(http.StatusOK, map[string]interface{}{
-        "message": "User registered successfully",
-        "user_id": string(userID),
-        "email": req.Email,
-    })
+	return c.JSON(http.StatusCreated, RegisterResponse{
+		Message: "User registered successfully, check your email to verify your account",
+		User:    UserResponse{ID: user.ID, Name: user.Name, Email: user.Email},
+	})
 }
 
 // @Summary Login a user
@@ -107,46 +90,25 @@ func Register(c echo.Context) error {
 // @Accept  json
 // @Produce  json
 // @Param request body LoginRequest true "User login data"
-// @Success 200 {object} LoginResponse "Authentication successful with a JWT token"
-// @Failure 400 {object} map[string]string "Invalid email or password"
-// @Failure 500 {object} map[string]string "Internal server error"
+// @Success 200 {object} LoginResponse "Authentication successful with an access and a refresh token"
+// @Failure 400 {object} apperror.Error "Invalid request"
+// @Failure 401 {object} apperror.Error "Invalid email or password"
+// @Failure 500 {object} apperror.Error "Internal server error"
 // @Router /users/login [post]
-func Login(c echo.Context) error {
+func (h *Handler) Login(c echo.Context) error {
 	var req LoginRequest
 	if err := c.Bind(&req); err != nil {
-		return c.JSON(http.StatusBadRequest, map[string]string{"message":"Invalid Request"})
+		return apperror.BadRequest("Invalid Request")
 	}
-	
-	var user Users
-	query := "SELECT user_id, email, password FROM users WHERE email = $1"
-	err := config.Pool.QueryRow(context.Background(), query, req.Email).Scan(&user.ID, &user.Email, &user.Password)
-	if err != nil {
-		return c.JSON(http.StatusBadRequest, map[string]string{"message": "Invalid email or password"})
-	}
-
-	// compare password to see if it matches the student password provided
-	if err := bcrypt.CompareHashAndPassword([]byte(user.Password), []byte(req.Password)); err != nil {
-		return c.JSON(http.StatusBadRequest, map[string]string{"message": "Invalid email or password"})
+	if err := c.Validate(&req); err != nil {
+		return err
 	}
 
-	// create new jwt claims
-	token := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{
-		"user_id": user.ID,
-		"exp":     jwt.NewNumericDate(time.Now().Add(72 * time.Hour)), // Use `jwt.NewNumericDate` for expiry
-	})
-	
-	tokenString, err := token.SignedString(jwtSecret)
+	resp, err := h.users.Login(c.Request().Context(), req.Email, req.Password)
 	if err != nil {
-		return c.JSON(http.StatusInternalServerError, map[string]string{"message": "Invalid Generate Token"})
-	}
-
-	// Update the jwt_token column in the database
-	updateQuery := "UPDATE users SET jwt_token = $1 WHERE user_id = $2"
-	_, err = config.Pool.Exec(context.Background(), updateQuery, tokenString, user.ID)
-	if err != nil {
-		return c.JSON(http.StatusInternalServerError, map[string]string{"message": "Failed to update token"})
+		return err
 	}
 
 	// return ok status and login response
-	return c.JSON(http.StatusOK, LoginResponse{Token: tokenString})
-}
\ No newline at end of file
+	return c.JSON(http.StatusOK, resp)
+}
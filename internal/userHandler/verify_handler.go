@@ -0,0 +1,77 @@
+package handler
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+	"time"
+
+	"w4/lc3/internal/apperror"
+	"w4/lc3/internal/auth"
+
+	"github.com/labstack/echo/v4"
+)
+
+// VerifyEmailRequest struct
+type VerifyEmailRequest struct {
+	Token string `json:"token" validate:"required"`
+}
+
+// @Summary Verify an email address
+// @Description Activate the account with the token from the verification email. Verifying twice is not an error.
+// @Tags Users
+// @Accept  json
+// @Produce  json
+// @Param request body VerifyEmailRequest true "Verification token"
+// @Success 200 {object} MessageResponse "Email verified"
+// @Failure 400 {object} apperror.Error "Invalid request, invalid or expired token"
+// @Failure 500 {object} apperror.Error "Internal server error"
+// @Router /users/verify [post]
+func (h *Handler) VerifyEmail(c echo.Context) error {
+	var req VerifyEmailRequest
+	if err := c.Bind(&req); err != nil {
+		return apperror.BadRequest("Invalid Request")
+	}
+	if err := c.Validate(&req); err != nil {
+		return err
+	}
+
+	if err := h.users.VerifyEmail(c.Request().Context(), req.Token); err != nil {
+		return err
+	}
+
+	return c.JSON(http.StatusOK, MessageResponse{Message: "Email verified"})
+}
+
+// @Summary Resend the verification email
+// @Description Send a new verification link to the email of the logged in user, at most once a minute
+// @Tags Users
+// @Produce  json
+// @Success 202 {object} MessageResponse "Verification email sent"
+// @Failure 401 {object} apperror.Error "Unauthorized"
+// @Failure 409 {object} apperror.Error "Email already verified"
+// @Failure 429 {object} apperror.Error "A verification email was sent too recently"
+// @Failure 500 {object} apperror.Error "Internal server error"
+// @Router /users/verify/resend [post]
+func (h *Handler) ResendVerification(c echo.Context) error {
+	// Extract user ID from the authenticated principal
+	principal, err := auth.CurrentPrincipal(c)
+	if err != nil {
+		return err
+	}
+	userID := principal.UserID
+
+	ctx, cancel := context.WithTimeout(c.Request().Context(), 10*time.Second)
+	defer cancel()
+
+	// A refusal because of the cooldown tells the client when to try again
+	retryAfter, err := h.users.ResendVerification(ctx, userID)
+	if retryAfter > 0 {
+		c.Response().Header().Set("Retry-After", strconv.Itoa(retryAfter))
+	}
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(http.StatusAccepted, MessageResponse{Message: "Verification email sent"})
+}
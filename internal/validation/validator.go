@@ -0,0 +1,132 @@
+package validation
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+	"unicode"
+
+	"w4/lc3/internal/apperror"
+
+	"github.com/go-playground/validator/v10"
+)
+
+// CustomValidator enforces the `validate` struct tags for echo's c.Validate.
+// Install it with e.Validator = validation.New().
+type CustomValidator struct {
+	validate *validator.Validate
+}
+
+// New returns a validator with the custom name, password and notblank rules registered
+func New() *CustomValidator {
+	v := validator.New(validator.WithRequiredStructEnabled())
+
+	// report fields by their json name so errors match the request body
+	v.RegisterTagNameFunc(func(field reflect.StructField) string {
+		name := strings.SplitN(field.Tag.Get("json"), ",", 2)[0]
+		if name == "-" || name == "" {
+			return field.Name
+		}
+		return name
+	})
+
+	v.RegisterValidation("name", validateName)
+	v.RegisterValidation("password", validatePassword)
+	v.RegisterValidation("notblank", validateNotBlank)
+
+	return &CustomValidator{validate: v}
+}
+
+// Validate checks i and returns an apperror listing every violated field
+func (cv *CustomValidator) Validate(i interface{}) error {
+	err := cv.validate.Struct(i)
+	if err == nil {
+		return nil
+	}
+
+	var validationErrs validator.ValidationErrors
+	if !errors.As(err, &validationErrs) {
+		return apperror.Internal("Failed to validate request", err)
+	}
+
+	fields := make([]apperror.FieldError, 0, len(validationErrs))
+	for _, fe := range validationErrs {
+		fields = append(fields, apperror.FieldError{
+			Field:   fe.Field(),
+			Rule:    fe.Tag(),
+			Message: message(fe),
+		})
+	}
+	return apperror.Validation(fields)
+}
+
+// validateName accepts 2 to 100 characters of letters, spaces, apostrophes, hyphens and dots
+func validateName(fl validator.FieldLevel) bool {
+	name := strings.TrimSpace(fl.Field().String())
+	if len([]rune(name)) < 2 || len([]rune(name)) > 100 {
+		return false
+	}
+	for _, r := range name {
+		if !unicode.IsLetter(r) && r != ' ' && r != '\'' && r != '-' && r != '.' {
+			return false
+		}
+	}
+	return true
+}
+
+// validatePassword requires 8 to 72 bytes (bcrypt ignores anything longer) with at least one letter and one digit
+func validatePassword(fl validator.FieldLevel) bool {
+	password := fl.Field().String()
+	if len(password) < 8 || len(password) > 72 {
+		return false
+	}
+	var hasLetter, hasDigit bool
+	for _, r := range password {
+		hasLetter = hasLetter || unicode.IsLetter(r)
+		hasDigit = hasDigit || unicode.IsDigit(r)
+	}
+	return hasLetter && hasDigit
+}
+
+// validateNotBlank rejects strings made only of whitespace
+func validateNotBlank(fl validator.FieldLevel) bool {
+	return strings.TrimSpace(fl.Field().String()) != ""
+}
+
+// message turns a failed rule into a human readable sentence
+func message(fe validator.FieldError) string {
+	field := fe.Field()
+	isString := fe.Kind() == reflect.String
+
+	switch fe.Tag() {
+	case "required":
+		return field + " is required"
+	case "notblank":
+		return field + " must not be blank"
+	case "email":
+		return field + " must be a valid email address"
+	case "name":
+		return field + " must be 2-100 characters of letters, spaces, apostrophes, hyphens or dots"
+	case "password":
+		return field + " must be 8-72 characters and contain at least one letter and one digit"
+	case "min":
+		if isString {
+			return fmt.Sprintf("%s must be at least %s characters", field, fe.Param())
+		}
+		return fmt.Sprintf("%s must be at least %s", field, fe.Param())
+	case "max":
+		if isString {
+			return fmt.Sprintf("%s must be at most %s characters", field, fe.Param())
+		}
+		return fmt.Sprintf("%s must be at most %s", field, fe.Param())
+	case "gt":
+		return fmt.Sprintf("%s must be greater than %s", field, fe.Param())
+	case "ne":
+		return fmt.Sprintf("%s must not be %s", field, fe.Param())
+	case "oneof":
+		return fmt.Sprintf("%s must be one of: %s", field, strings.ReplaceAll(fe.Param(), " ", ", "))
+	default:
+		return fmt.Sprintf("%s failed the %s rule", field, fe.Tag())
+	}
+}
@@ -0,0 +1,136 @@
+package main
+
+import (
+	"fmt"
+
+	_ "w4/lc3/docs"
+
+	config "w4/lc3/config/database"
+	"w4/lc3/internal/apperror"
+	"w4/lc3/internal/auth"
+	cart_handler "w4/lc3/internal/cartHandler"
+	category_handler "w4/lc3/internal/categoryHandler"
+	"w4/lc3/internal/mailer"
+	cust_middleware "w4/lc3/internal/middleware"
+	order_handler "w4/lc3/internal/orderHandler"
+	product_handler "w4/lc3/internal/productHandler"
+	"w4/lc3/internal/repository"
+	"w4/lc3/internal/repository/postgres"
+	"w4/lc3/internal/service"
+	user_handler "w4/lc3/internal/userHandler"
+	"w4/lc3/internal/validation"
+
+	"github.com/labstack/echo/v4"
+	"github.com/labstack/echo/v4/middleware"
+	echoSwagger "github.com/swaggo/echo-swagger"
+	"github.com/urfave/cli/v2"
+)
+
+// serve starts the HTTP API
+func serve(c *cli.Context) error {
+	// connect to db
+	if err := config.InitDB(dbSettings); err != nil {
+		return err
+	}
+	defer config.CloseDB()
+
+	// load the JWT signing keys
+	if err := auth.Init(); err != nil {
+		return fmt.Errorf("failed to load JWT keys: %w", err)
+	}
+
+	// configure how emails are sent
+	if err := mailer.Init(); err != nil {
+		return fmt.Errorf("failed to configure mailer: %w", err)
+	}
+
+	e := newServer(postgres.New(config.Pool), auth.Keys, mailer.Default)
+	e.Use(middleware.Logger())
+	e.Use(middleware.Recover())
+
+	// start the server, on 8080 by default
+	return e.Start(c.String("addr"))
+}
+
+// newServer builds the API on store: the services, the handlers using them and the routes.
+// Tests build it on the in-memory store.
+func newServer(store repository.Store, keys *auth.KeySet, mail mailer.Mailer) *echo.Echo {
+	e := echo.New()
+	e.HTTPErrorHandler = apperror.HTTPErrorHandler
+	e.Validator = validation.New()
+
+	users := service.NewUserService(store, keys, mail)
+	registerRoutes(e, handlers{
+		users:      user_handler.New(users),
+		products:   product_handler.New(service.NewProductService(store)),
+		categories: category_handler.New(service.NewCategoryService(store)),
+		carts:      cart_handler.New(service.NewCartService(store)),
+		orders:     order_handler.New(service.NewOrderService(store)),
+		jwt:        cust_middleware.NewJWTMiddleware(keys, users),
+	})
+	return e
+}
+
+// handlers holds everything the routes dispatch to
+type handlers struct {
+	users      *user_handler.Handler
+	products   *product_handler.Handler
+	categories *category_handler.Handler
+	carts      *cart_handler.Handler
+	orders     *order_handler.Handler
+	jwt        echo.MiddlewareFunc
+}
+
+func registerRoutes(e *echo.Echo, h handlers) {
+	// public routes
+	e.POST("users/register", h.users.Register)
+	e.POST("users/login", h.users.Login)
+	e.POST("users/refresh", h.users.Refresh)
+	e.POST("users/password/forgot", h.users.ForgotPassword)
+	e.POST("users/password/reset", h.users.ResetPassword)
+	e.POST("users/verify", h.users.VerifyEmail)
+	e.POST("users/verify/resend", h.users.ResendVerification, h.jwt)
+
+	// profile
+	e.GET("users/me", h.users.GetProfile, h.jwt)
+	e.PATCH("users/me", h.users.UpdateProfile, h.jwt)
+	e.DELETE("users/me", h.users.DeleteAccount, h.jwt)
+	e.POST("users/me/password", h.users.ChangePassword, h.jwt)
+	e.POST("users/logout", h.users.Logout, h.jwt)
+	e.GET("/.well-known/jwks.json", h.users.JWKS)
+
+	// products
+	e.GET("products", h.products.GetAllProducts)
+	e.GET("products/search", h.products.SearchProducts)
+	e.GET("products/:id", h.products.GetProductByID)
+
+	// categories
+	e.GET("categories", h.categories.GetCategories)
+	e.GET("categories/:id/products", h.categories.GetCategoryProducts)
+
+	// protected routes //
+	// carts
+	e.GET("users/carts", h.carts.GetCart, h.jwt)
+	e.POST("users/carts", h.carts.AddToCart, h.jwt)
+	e.DELETE("users/carts/:id", h.carts.DeleteCartItem, h.jwt)
+	e.PATCH("users/carts/:id", h.carts.UpdateCartItem, h.jwt)
+	e.DELETE("users/carts", h.carts.ClearCart, h.jwt)
+
+	// orders
+	e.GET("users/orders", h.orders.GetOrders, h.jwt)
+	e.GET("users/orders/:id", h.orders.GetOrderByID, h.jwt)
+	e.POST("users/orders/:id/cancel", h.orders.CancelOrder, h.jwt)
+
+	// admin routes //
+	adminOnly := cust_middleware.RequireRole(cust_middleware.RoleAdmin)
+	e.PATCH("admin/orders/:id/status", h.orders.UpdateOrderStatus, h.jwt, adminOnly)
+	e.POST("products", h.products.CreateProduct, h.jwt, adminOnly)
+	e.PUT("products/:id", h.products.UpdateProduct, h.jwt, adminOnly)
+	e.PATCH("products/:id", h.products.PatchProduct, h.jwt, adminOnly)
+	e.DELETE("products/:id", h.products.DeleteProduct, h.jwt, adminOnly)
+	e.POST("admin/products/:id/stock", h.products.AdjustStock, h.jwt, adminOnly)
+	e.POST("users/orders", h.orders.AddOrder, h.jwt)
+
+	// swagger
+	e.GET("/swagger/*", echoSwagger.WrapHandler)
+}